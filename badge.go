@@ -0,0 +1,154 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultBadgeColors are the color bands used for badges if none are configured.
+const defaultBadgeColors = "0:red,50:yellow,70:yellowgreen,80:green,90:brightgreen"
+
+// badgeColorNames are the named colors that can be used for badge color bands.
+var badgeColorNames = map[string]string{
+	"red":         "#e05d44",
+	"orange":      "#fe7d37",
+	"yellow":      "#dfb317",
+	"yellowgreen": "#a4a61d",
+	"green":       "#97ca00",
+	"brightgreen": "#4c1",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+}
+
+// BadgeColor is a color band of a badge. It applies to every coverage of at least
+// Threshold percent, unless a band with a higher threshold applies as well.
+type BadgeColor struct {
+	Threshold float64
+	Color     string
+}
+
+// parseBadgeColors parses a comma separated list of PERCENT:COLOR color bands. COLOR
+// is either one of badgeColorNames or a hex color like #4c1.
+func parseBadgeColors(s string) ([]BadgeColor, error) {
+	var colors []BadgeColor
+	for band := range strings.SplitSeq(s, ",") {
+		threshold, color, ok := strings.Cut(strings.TrimSpace(band), ":")
+		if !ok {
+			return nil, fmt.Errorf("color band %q is not of the form PERCENT:COLOR", band)
+		}
+		t, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return nil, fmt.Errorf("threshold of color band %q: %w", band, err)
+		}
+		if named, ok := badgeColorNames[color]; ok {
+			color = named
+		} else if !strings.HasPrefix(color, "#") {
+			return nil, fmt.Errorf("unknown color %q in color band %q", color, band)
+		}
+		colors = append(colors, BadgeColor{Threshold: t, Color: color})
+	}
+	slices.SortFunc(colors, func(a, b BadgeColor) int {
+		return cmp.Compare(a.Threshold, b.Threshold)
+	})
+	return colors, nil
+}
+
+// writeBadges writes the coverage badge of the whole report to opts.BadgeFile and one
+// badge for every package into opts.BadgePackagesDir, if they are set.
+func writeBadges(coverage *Coverage, opts *Options) error {
+	if opts.BadgeFile != "" {
+		if err := writeBadgeFile(opts.BadgeFile, float64(coverage.LineRate), opts); err != nil {
+			return err
+		}
+	}
+	if opts.BadgePackagesDir != "" {
+		for _, pkg := range coverage.Packages {
+			name := filepath.Join(opts.BadgePackagesDir, filepath.FromSlash(pkg.Name)+".svg")
+			if err := writeBadgeFile(name, float64(pkg.LineRate), opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeBadgeFile(name string, rate float64, opts *Options) error {
+	err := os.MkdirAll(filepath.Dir(name), 0o755)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("create directory for %s: %w", name, err)
+	}
+	out, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	defer out.Close()
+
+	label := opts.BadgeLabel
+	if label == "" {
+		label = "coverage"
+	}
+	if err := writeBadge(out, label, rate, opts.BadgeColors); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return out.Close()
+}
+
+// writeBadge writes a flat SVG badge showing label and the coverage rate (0.0 to 1.0)
+// as percentage, colored according to the given color bands.
+func writeBadge(w io.Writer, label string, rate float64, colors []BadgeColor) error {
+	percent := math.Floor(rate * 100)
+	value := strconv.FormatFloat(percent, 'f', 0, 64) + "%"
+	color := badgeColorNames["lightgrey"]
+	for _, band := range colors {
+		if percent >= band.Threshold {
+			color = band.Color
+		}
+	}
+
+	labelWidth := badgeTextWidth(label)
+	valueWidth := badgeTextWidth(value)
+	width := labelWidth + valueWidth
+	title := html.EscapeString(label + ": " + value)
+	label = html.EscapeString(label)
+
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" role="img" aria-label="%[2]s"
+  width="%[1]d" height="20">
+  <title>%[2]s</title>
+  <linearGradient id="s" x2="0" y2="100%%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="%[1]d" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[3]d" height="20" fill="#555"/>
+    <rect x="%[3]d" width="%[4]d" height="20" fill="%[5]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[6]d" y="15" fill="#010101" fill-opacity=".3">%[7]s</text>
+    <text x="%[6]d" y="14">%[7]s</text>
+    <text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[9]s</text>
+    <text x="%[8]d" y="14">%[9]s</text>
+  </g>
+</svg>
+`, width, title, labelWidth, valueWidth, color, labelWidth/2, label, labelWidth+valueWidth/2, value)
+	return err
+}
+
+// badgeTextWidth approximates the width in pixels of a badge section containing s.
+func badgeTextWidth(s string) int {
+	const charWidth, padding = 7, 10
+	return len([]rune(s))*charWidth + padding
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseBadgeColors(t *testing.T) {
+	t.Parallel()
+
+	colors, err := parseBadgeColors("80:green, 0:red,50:#123456")
+	if err != nil {
+		t.Fatalf("parseBadgeColors failed: %v", err)
+	}
+	expected := []BadgeColor{
+		{Threshold: 0, Color: badgeColorNames["red"]},
+		{Threshold: 50, Color: "#123456"},
+		{Threshold: 80, Color: badgeColorNames["green"]},
+	}
+	if len(colors) != len(expected) {
+		t.Fatalf("expected %d colors, got %d", len(expected), len(colors))
+	}
+	for i := range expected {
+		if colors[i] != expected[i] {
+			t.Errorf("expected color band %v, got %v", expected[i], colors[i])
+		}
+	}
+
+	for _, bad := range []string{"green", "x:green", "50:purple"} {
+		if _, err := parseBadgeColors(bad); err == nil {
+			t.Errorf("expected error for color bands %q", bad)
+		}
+	}
+}
+
+func TestWriteBadge(t *testing.T) {
+	t.Parallel()
+
+	colors, err := parseBadgeColors(defaultBadgeColors)
+	if err != nil {
+		t.Fatalf("parseBadgeColors failed: %v", err)
+	}
+
+	tt := []struct {
+		rate  float64
+		value string
+		color string
+	}{
+		{0, "0%", "red"},
+		{0.499, "49%", "red"},
+		{0.5, "50%", "yellow"},
+		{0.85, "85%", "green"},
+		{1, "100%", "brightgreen"},
+	}
+	for _, tc := range tt {
+		out := new(bytes.Buffer)
+		if err := writeBadge(out, "cover <age>", tc.rate, colors); err != nil {
+			t.Fatalf("writeBadge failed: %v", err)
+		}
+		svg := out.String()
+		if !strings.Contains(svg, `aria-label="cover &lt;age&gt;: `+tc.value+`"`) {
+			t.Errorf("expected badge with value %s:\n%s", tc.value, svg)
+		}
+		if !strings.Contains(svg, `fill="`+badgeColorNames[tc.color]+`"`) {
+			t.Errorf("expected badge with color %s for %s:\n%s", tc.color, tc.value, svg)
+		}
+	}
+}
+
+func TestConvertBadges(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	convertTestdata(t, "testdata/testdata_set.txt", &Options{
+		BadgeFile:        filepath.Join(dir, "coverage.svg"),
+		BadgePackagesDir: filepath.Join(dir, "packages"),
+		BadgeLabel:       "go",
+	})
+
+	for _, name := range []string{
+		filepath.Join(dir, "coverage.svg"),
+		filepath.Join(dir, "packages", "github.com", "fasmat", "gocover-cobertura", "testdata.svg"),
+	} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read badge: %v", err)
+		}
+		if !strings.Contains(string(data), `aria-label="go: 75%"`) {
+			t.Errorf("unexpected badge %s:\n%s", name, data)
+		}
+	}
+}
+
+func TestConvertBadgesStatementRates(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "coverage.svg")
+	convertTestdata(t, "testdata/testdata_statements.txt", &Options{
+		Ignore:         &Ignore{},
+		BadgeFile:      name,
+		BadgeLabel:     "go",
+		StatementRates: true,
+	})
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("failed to read badge: %v", err)
+	}
+	if !strings.Contains(string(data), `aria-label="go: 60%"`) {
+		t.Errorf("expected badge with the statement rate of 60%%:\n%s", data)
+	}
+}
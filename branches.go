@@ -0,0 +1,292 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/cover"
+)
+
+// branchAnalyzer derives branch coverage of a function from its AST and the profile
+// blocks of the function.
+//
+// Go profiles have a block for every basic block of a function, so the outcomes of
+// if, switch, select and for statements can be matched against the blocks of their
+// bodies. Outcomes without a block of their own, like the implicit else of an if, are
+// derived from the hit counts of the surrounding blocks; branches whose implicit outcome
+// cannot be derived are not recorded. Profiles have no blocks inside of expressions, so
+// the outcomes of && and || in conditions are approximated by the outcomes of the
+// condition they are part of.
+type branchAnalyzer struct {
+	fset   *token.FileSet
+	mode   string
+	blocks []cover.ProfileBlock
+	lines  map[int]*Line
+	// reportedLine maps the lines of the profile to the numbers of lines.
+	reportedLine func(line int) int
+
+	// next maps every statement to the statement following it in its statement list.
+	next map[ast.Stmt]ast.Stmt
+}
+
+// branches records the branch outcomes of the function body on the lines of method.
+func (v *fileVisitor) branches(body *ast.BlockStmt, method *Method) {
+	if body == nil {
+		return
+	}
+	b := &branchAnalyzer{
+		fset:   v.fset,
+		mode:   v.profile.Mode,
+		blocks: method.blocks,
+		lines:  make(map[int]*Line, len(method.Lines)),
+		next:   make(map[ast.Stmt]ast.Stmt),
+
+		reportedLine: v.reportedLine,
+	}
+	for _, line := range method.Lines {
+		b.lines[line.Number] = line
+	}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		var list []ast.Stmt
+		switch n := node.(type) {
+		case *ast.BlockStmt:
+			list = n.List
+		case *ast.CaseClause:
+			list = n.Body
+		case *ast.CommClause:
+			list = n.Body
+		}
+		for i := 1; i < len(list); i++ {
+			b.next[list[i-1]] = list[i]
+		}
+		return true
+	})
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.IfStmt:
+			b.ifStmt(n, b.next[n])
+		case *ast.SwitchStmt:
+			b.switchStmt(n.Switch, n.Body, b.next[n])
+		case *ast.TypeSwitchStmt:
+			b.switchStmt(n.Switch, n.Body, b.next[n])
+		case *ast.SelectStmt:
+			b.selectStmt(n)
+		case *ast.ForStmt:
+			if n.Cond != nil {
+				b.loop(n.For, n.Cond, n.Body, b.next[n])
+			}
+		case *ast.RangeStmt:
+			b.loop(n.For, nil, n.Body, b.next[n])
+		}
+		return true
+	})
+}
+
+func (b *branchAnalyzer) ifStmt(n *ast.IfStmt, next ast.Stmt) {
+	entry := b.countAt(n.If)
+	then := b.countIn(n.Body.Pos(), n.Body.End())
+	var otherwise int
+	if n.Else != nil {
+		// The else branch starts right after the body, which for an else if includes its condition.
+		otherwise = b.countIn(n.Body.End(), n.Else.End())
+	} else {
+		var ok bool
+		otherwise, ok = b.implicit(entry, []int{then}, []*ast.BlockStmt{n.Body}, next)
+		if !ok {
+			return
+		}
+	}
+	b.record(n.If, entry, then, otherwise)
+	b.conditions(n.If, n.Cond, entry, then > 0, otherwise > 0)
+
+	if elseIf, ok := n.Else.(*ast.IfStmt); ok {
+		// The else if is not part of a statement list, but continues where n does.
+		b.next[elseIf] = next
+	}
+}
+
+func (b *branchAnalyzer) switchStmt(pos token.Pos, body *ast.BlockStmt, next ast.Stmt) {
+	entry := b.countAt(pos)
+	var counts []int
+	var bodies []*ast.BlockStmt
+	hasDefault := false
+	for _, stmt := range body.List {
+		clause := stmt.(*ast.CaseClause)
+		counts = append(counts, b.countIn(clause.Colon+1, clause.End()))
+		bodies = append(bodies, &ast.BlockStmt{List: clause.Body})
+		hasDefault = hasDefault || clause.List == nil
+	}
+	outcomes := counts
+	if !hasDefault {
+		otherwise, ok := b.implicit(entry, counts, bodies, next)
+		if !ok {
+			return
+		}
+		outcomes = append(outcomes, otherwise)
+	}
+	b.record(pos, entry, outcomes...)
+}
+
+func (b *branchAnalyzer) selectStmt(n *ast.SelectStmt) {
+	entry := b.countAt(n.Select)
+	var outcomes []int
+	for _, stmt := range n.Body.List {
+		clause := stmt.(*ast.CommClause)
+		outcomes = append(outcomes, b.countIn(clause.Colon+1, clause.End()))
+	}
+	b.record(n.Select, entry, outcomes...)
+}
+
+func (b *branchAnalyzer) loop(pos token.Pos, cond ast.Expr, body *ast.BlockStmt, next ast.Stmt) {
+	entry := b.countAt(pos)
+	iterations := b.countIn(body.Pos(), body.End())
+	// The loop is left when the statement after it is reached; if there is none assume it was
+	// left whenever it was entered.
+	exit := entry
+	if next != nil {
+		exit = min(entry, b.countIn(next.Pos(), next.End()))
+	}
+	b.record(pos, entry, iterations, exit)
+	if cond != nil {
+		b.conditions(pos, cond, entry, iterations > 0, exit > 0)
+	}
+}
+
+// conditions records two outcomes for every && and || in cond: the right operand is
+// evaluated or skipped. For && the right operand is evaluated whenever the condition is
+// true and assumed to be skipped when it is false, for || the other way around. The
+// skipped outcome is an upper bound: a && b is also false if a is always true and only b
+// is ever false, which the profile cannot tell apart.
+func (b *branchAnalyzer) conditions(pos token.Pos, cond ast.Expr, entry int, isTrue, isFalse bool) {
+	ast.Inspect(cond, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.BinaryExpr:
+			evaluated, skipped := isTrue, isFalse
+			switch n.Op {
+			case token.LAND:
+			case token.LOR:
+				evaluated, skipped = isFalse, isTrue
+			default:
+				return true
+			}
+			b.record(pos, entry, boolCount(evaluated), boolCount(skipped))
+		}
+		return true
+	})
+}
+
+// record adds the outcomes of a branch at pos to its line. An outcome is covered if its
+// count is > 0 and the branch was reached at all.
+func (b *branchAnalyzer) record(pos token.Pos, entry int, outcomes ...int) {
+	line := b.lines[b.reportedLine(b.fset.PositionFor(pos, false).Line)]
+	if line == nil || len(outcomes) == 0 {
+		return
+	}
+	var covered int64
+	for _, count := range outcomes {
+		if entry > 0 && count > 0 {
+			covered++
+		}
+	}
+	line.addBranches(int64(len(outcomes)), covered)
+}
+
+// implicit returns the count of an outcome without a block of its own, like the missing
+// else of an if or the missing default of a switch, given the counts and bodies of the
+// explicit outcomes and the statement following the branch. It returns false if the count
+// cannot be derived, which happens in set mode when an explicit outcome was taken and
+// continues with the statement after the branch like the implicit outcome does.
+func (b *branchAnalyzer) implicit(entry int, counts []int, bodies []*ast.BlockStmt, next ast.Stmt) (int, bool) {
+	taken := 0
+	for _, count := range counts {
+		taken += count
+	}
+	if b.mode != "set" {
+		// Hit counts are exact, so the implicit outcome is what is left.
+		return max(entry-taken, 0), true
+	}
+	if entry == 0 {
+		return 0, true
+	}
+	if taken == 0 {
+		return entry, true
+	}
+	// If every explicit outcome leaves the enclosing block, the statement after the branch
+	// can only have been reached by the implicit outcome.
+	for _, body := range bodies {
+		if !terminates(body) {
+			return 0, false
+		}
+	}
+	if next == nil {
+		// Nothing follows the branch, so the implicit outcome leaves the block unnoticed.
+		return 0, false
+	}
+	return b.countIn(next.Pos(), next.End()), true
+}
+
+// countAt returns the hit count of the block containing pos.
+func (b *branchAnalyzer) countAt(pos token.Pos) int {
+	p := b.fset.PositionFor(pos, false)
+	for _, block := range b.blocks {
+		if positionBefore(p.Line, p.Column, block.StartLine, block.StartCol) {
+			break
+		}
+		if positionBefore(p.Line, p.Column, block.EndLine, block.EndCol) {
+			return block.Count
+		}
+	}
+	return 0
+}
+
+// countIn returns the hit count of the first block starting in [start, end).
+func (b *branchAnalyzer) countIn(start, end token.Pos) int {
+	s := b.fset.PositionFor(start, false)
+	e := b.fset.PositionFor(end, false)
+	for _, block := range b.blocks {
+		if positionBefore(block.StartLine, block.StartCol, s.Line, s.Column) {
+			continue
+		}
+		if positionBefore(block.StartLine, block.StartCol, e.Line, e.Column) {
+			return block.Count
+		}
+		break
+	}
+	return 0
+}
+
+// terminates reports whether the last statement of body leaves the enclosing block.
+func terminates(body *ast.BlockStmt) bool {
+	if len(body.List) == 0 {
+		return false
+	}
+	switch stmt := body.List[len(body.List)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return stmt.Tok != token.FALLTHROUGH
+	case *ast.ExprStmt:
+		call, ok := stmt.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		ident, ok := call.Fun.(*ast.Ident)
+		return ok && ident.Name == "panic"
+	}
+	return false
+}
+
+// positionBefore reports whether line1:col1 is before line2:col2.
+func positionBefore(line1, col1, line2, col2 int) bool {
+	return line1 < line2 || (line1 == line2 && col1 < col2)
+}
+
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
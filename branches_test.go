@@ -0,0 +1,176 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/cover"
+)
+
+func TestConvertBranches(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_branches.txt", &Options{Ignore: &Ignore{}})
+
+	v := decodeCoverage(t, out)
+	if v.BranchesValid != 17 || v.BranchesCovered != 12 {
+		t.Errorf("expected 12 of 17 branches covered, got %d of %d", v.BranchesCovered, v.BranchesValid)
+	}
+
+	expected := map[string]struct {
+		branchRate float32
+		conditions map[int]string
+	}{
+		"If":       {1, map[int]string{6: "100% (4/4)"}},
+		"IfElse":   {0.25, map[int]string{13: "50% (1/2)", 15: "0% (0/2)"}},
+		"Switch":   {2.0 / 3, map[int]string{23: "66% (2/3)"}},
+		"For":      {1, map[int]string{34: "100% (4/4)"}},
+		"Select":   {0.5, map[int]string{41: "50% (1/2)"}},
+		"Straight": {1, map[int]string{}},
+	}
+
+	methods := v.Packages[0].Classes[0].Methods
+	if len(methods) != len(expected) {
+		t.Fatalf("expected %d methods, got %d", len(expected), len(methods))
+	}
+	for _, m := range methods {
+		e := expected[m.Name]
+		if m.BranchRate != e.branchRate {
+			t.Errorf("expected branch rate %f for %s, got %f", e.branchRate, m.Name, m.BranchRate)
+		}
+		for _, line := range m.Lines {
+			condition, ok := e.conditions[line.Number]
+			if line.Branch != ok || line.ConditionCoverage != condition {
+				t.Errorf("expected condition coverage %q on line %d of %s, got %q (branch=%t)",
+					condition, line.Number, m.Name, line.ConditionCoverage, line.Branch)
+			}
+		}
+	}
+}
+
+func TestBranchesSetMode(t *testing.T) {
+	t.Parallel()
+
+	const src = `package p
+
+func f(a bool) int {
+	if a {
+		return 1
+	}
+	return 0
+}
+`
+	tt := []struct {
+		name     string
+		blocks   []cover.ProfileBlock
+		expected string
+	}{
+		{
+			name: "then",
+			blocks: []cover.ProfileBlock{
+				{StartLine: 3, StartCol: 20, EndLine: 4, EndCol: 7, Count: 1},
+				{StartLine: 4, StartCol: 7, EndLine: 6, EndCol: 3, Count: 1},
+				{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, Count: 0},
+			},
+			expected: "50% (1/2)",
+		},
+		{
+			name: "else",
+			blocks: []cover.ProfileBlock{
+				{StartLine: 3, StartCol: 20, EndLine: 4, EndCol: 7, Count: 1},
+				{StartLine: 4, StartCol: 7, EndLine: 6, EndCol: 3, Count: 0},
+				{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, Count: 1},
+			},
+			expected: "50% (1/2)",
+		},
+		{
+			name: "both",
+			blocks: []cover.ProfileBlock{
+				{StartLine: 3, StartCol: 20, EndLine: 4, EndCol: 7, Count: 1},
+				{StartLine: 4, StartCol: 7, EndLine: 6, EndCol: 3, Count: 1},
+				{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, Count: 1},
+			},
+			expected: "100% (2/2)",
+		},
+		{
+			name: "none",
+			blocks: []cover.ProfileBlock{
+				{StartLine: 3, StartCol: 20, EndLine: 4, EndCol: 7, Count: 0},
+				{StartLine: 4, StartCol: 7, EndLine: 6, EndCol: 3, Count: 0},
+				{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, Count: 0},
+			},
+			expected: "0% (0/2)",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fset := token.NewFileSet()
+			parsed, err := parser.ParseFile(fset, "p.go", src, 0)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+			v := &fileVisitor{
+				fset:     fset,
+				lineHits: "min",
+				profile:  &cover.Profile{Mode: "set", Blocks: tc.blocks},
+			}
+			fn := parsed.Decls[0].(*ast.FuncDecl)
+			method := v.method(fn, nil)
+			v.branches(fn.Body, method)
+
+			for _, line := range method.Lines {
+				if line.Number == 4 {
+					if line.ConditionCoverage != tc.expected {
+						t.Errorf("expected condition coverage %q, got %q", tc.expected, line.ConditionCoverage)
+					}
+					return
+				}
+			}
+			t.Fatalf("line 4 not found")
+		})
+	}
+}
+
+func TestBranchesSetModeUnknown(t *testing.T) {
+	t.Parallel()
+
+	const src = `package p
+
+func Inc(x int) int {
+	if x > 0 {
+		x++
+	}
+	return x
+}
+`
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	v := &fileVisitor{
+		fset:     fset,
+		lineHits: "min",
+		profile: &cover.Profile{Mode: "set", Blocks: []cover.ProfileBlock{
+			{StartLine: 3, StartCol: 21, EndLine: 4, EndCol: 11, Count: 1},
+			{StartLine: 4, StartCol: 11, EndLine: 6, EndCol: 3, Count: 1},
+			{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, Count: 1},
+		}},
+	}
+	fn := parsed.Decls[0].(*ast.FuncDecl)
+	method := v.method(fn, nil)
+	v.branches(fn.Body, method)
+
+	// Whether the implicit else was taken cannot be told from a set mode profile, as the body
+	// of the if continues with the return statement as well.
+	for _, line := range method.Lines {
+		if line.Branch || line.ConditionCoverage != "" {
+			t.Errorf("expected no branch on line %d, got %q", line.Number, line.ConditionCoverage)
+		}
+	}
+}
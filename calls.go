@@ -0,0 +1,115 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+type callsReport struct {
+	Functions []callsFunction `json:"functions"`
+	HotBlocks []callsBlock    `json:"hot_blocks"`
+}
+
+type callsFunction struct {
+	Package   string `json:"package"`
+	Class     string `json:"class"`
+	Function  string `json:"function"`
+	File      string `json:"file"`
+	StartLine int    `json:"start_line"`
+	Calls     int64  `json:"calls"`
+}
+
+type callsBlock struct {
+	Package    string `json:"package"`
+	Function   string `json:"function"`
+	File       string `json:"file"`
+	StartLine  int    `json:"start_line"`
+	StartCol   int    `json:"start_col"`
+	EndLine    int    `json:"end_line"`
+	EndCol     int    `json:"end_col"`
+	Statements int    `json:"statements"`
+	Count      int    `json:"count"`
+}
+
+// collectCalls returns how often every function of the report was entered and the
+// opts.HotBlocks blocks executed most often, both ordered by count. The counts are most
+// useful for profiles recorded with -covermode=count or -covermode=atomic.
+func collectCalls(coverage *Coverage, opts *Options) callsReport {
+	report := callsReport{Functions: []callsFunction{}, HotBlocks: []callsBlock{}}
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				report.Functions = append(report.Functions, callsFunction{
+					Package:   pkg.Name,
+					Class:     class.Name,
+					Function:  methodDisplayName(method, opts),
+					File:      method.Filename,
+					StartLine: method.StartLine,
+					Calls:     method.NumCalls(),
+				})
+				for _, b := range method.blocks {
+					report.HotBlocks = append(report.HotBlocks, callsBlock{
+						Package:    pkg.Name,
+						Function:   methodDisplayName(method, opts),
+						File:       method.Filename,
+						StartLine:  b.StartLine,
+						StartCol:   b.StartCol,
+						EndLine:    b.EndLine,
+						EndCol:     b.EndCol,
+						Statements: b.NumStmt,
+						Count:      b.Count,
+					})
+				}
+			}
+		}
+	}
+
+	// Sort stable by count only, so functions and blocks with the same count stay in the
+	// order of the report.
+	slices.SortStableFunc(report.Functions, func(a, b callsFunction) int {
+		return cmp.Compare(b.Calls, a.Calls)
+	})
+	slices.SortStableFunc(report.HotBlocks, func(a, b callsBlock) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	report.HotBlocks = report.HotBlocks[:min(opts.HotBlocks, len(report.HotBlocks))]
+	return report
+}
+
+// writeCalls writes how often every function was entered and the hottest blocks as JSON.
+func writeCalls(out io.Writer, coverage *Coverage, opts *Options) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collectCalls(coverage, opts)); err != nil {
+		return fmt.Errorf("encode calls report: %w", err)
+	}
+	return nil
+}
+
+// writeCallsCSV writes how often every function was entered as CSV, with one row for every
+// function ordered by calls.
+func writeCallsCSV(out io.Writer, coverage *Coverage, opts *Options) error {
+	w := csv.NewWriter(out)
+	_ = w.Write([]string{"package", "class", "file", "function", "start_line", "calls"})
+	for _, function := range collectCalls(coverage, opts).Functions {
+		_ = w.Write([]string{
+			function.Package,
+			function.Class,
+			function.File,
+			function.Function,
+			strconv.Itoa(function.StartLine),
+			strconv.FormatInt(function.Calls, 10),
+		})
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConvertCalls(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_branches.txt", &Options{
+		Ignore:    &Ignore{},
+		Format:    "calls",
+		HotBlocks: 3,
+	})
+
+	var report callsReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+
+	var functions []string
+	var calls []int64
+	for _, function := range report.Functions {
+		functions = append(functions, function.Function)
+		calls = append(calls, function.Calls)
+	}
+	expectedFunctions := []string{"If", "Switch", "IfElse", "For", "Select", "Straight"}
+	expectedCalls := []int64{2, 2, 1, 1, 1, 0}
+	if !slices.Equal(functions, expectedFunctions) || !slices.Equal(calls, expectedCalls) {
+		t.Errorf("expected functions %v with calls %v, got %v with %v",
+			expectedFunctions, expectedCalls, functions, calls)
+	}
+
+	if len(report.HotBlocks) != 3 {
+		t.Fatalf("expected 3 hot blocks, got %d", len(report.HotBlocks))
+	}
+	hottest := report.HotBlocks[0]
+	if hottest.Function != "For" || hottest.StartLine != 35 || hottest.Count != 3 {
+		t.Errorf("expected the body of For at line 35 with count 3 as hottest block, got %+v", hottest)
+	}
+	if report.HotBlocks[1].Count != 2 || report.HotBlocks[2].Count != 2 {
+		t.Errorf("expected blocks with count 2 next, got %+v", report.HotBlocks[1:])
+	}
+}
+
+func TestConvertCallsCSV(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_branches.txt", &Options{Ignore: &Ignore{}, Format: "calls-csv"})
+
+	rows, err := csv.NewReader(out).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	const pkg = "github.com/fasmat/gocover-cobertura/testdata"
+	expected := [][]string{
+		{"package", "class", "file", "function", "start_line", "calls"},
+		{pkg, "-", "testdata/branches.go", "If", "5", "2"},
+		{pkg, "-", "testdata/branches.go", "Switch", "22", "2"},
+	}
+	for i, row := range expected {
+		if !slices.Equal(rows[i], row) {
+			t.Errorf("expected row %v, got %v", row, rows[i])
+		}
+	}
+}
+
+func TestConvertCallAttributes(t *testing.T) {
+	t.Parallel()
+
+	v := decodeCoverage(t, convertTestdata(t, "testdata/testdata_branches.txt", &Options{
+		Ignore:         &Ignore{},
+		CallAttributes: true,
+	}))
+	m := v.Packages[0].Classes[0].Methods[0]
+	if m.Name != "If" || m.Calls == nil || *m.Calls != 2 {
+		t.Errorf("expected If to be called twice, got %s with %v", m.Name, m.Calls)
+	}
+}
+
+func TestConvertNegativeHotBlocks(t *testing.T) {
+	t.Parallel()
+
+	err := convert(strings.NewReader("mode: set"), new(bytes.Buffer), &Options{
+		Ignore:    &Ignore{},
+		Format:    "calls",
+		HotBlocks: -1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "negative number of hot blocks -1") {
+		t.Fatalf("expected error about negative hot blocks, got: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+// classGroupings maps the names accepted by -classes to the functions naming the class of
+// a function in a file, and the type parameters shown with -type-params. Every class
+// belongs to a single file, the "type" grouping only names and orders the classes of a
+// type in several files alike, see fileVisitor.addClass.
+var classGroupings = map[string]func(n *ast.FuncDecl, fileName string) (name, typeParams string){
+	// One class for every receiver type in every file, "-" for functions.
+	"receiver": func(n *ast.FuncDecl, _ string) (string, string) {
+		return recvType(n)
+	},
+	// Like receiver, but the classes of a type in several files are listed together.
+	"type": func(n *ast.FuncDecl, _ string) (string, string) {
+		return recvType(n)
+	},
+	// Like receiver, but functions returning a type of the package, like constructors,
+	// belong to the class of that type.
+	"constructors": func(n *ast.FuncDecl, _ string) (string, string) {
+		if n.Recv == nil {
+			if name, typeParams, ok := resultType(n); ok {
+				return name, typeParams
+			}
+		}
+		return recvType(n)
+	},
+	// Like receiver, but functions belong to a class named after their file instead of "-".
+	"files": func(n *ast.FuncDecl, fileName string) (string, string) {
+		if n.Recv == nil {
+			return filepath.Base(fileName), ""
+		}
+		return recvType(n)
+	},
+	// One class for every function, named like the function qualified with its receiver.
+	"functions": func(n *ast.FuncDecl, _ string) (string, string) {
+		if n.Recv == nil {
+			return n.Name.Name, ""
+		}
+		name, _ := recvType(n)
+		return name + "." + n.Name.Name, ""
+	},
+}
+
+// recvType returns the name of the receiver type of n without pointer and type parameters,
+// e.g. "List" for "func (l *List[T]) Push(v T)", and the type parameters as named by the
+// receiver, e.g. "[T]". Functions without receiver belong to the class "-".
+func recvType(n *ast.FuncDecl) (name, typeParams string) {
+	if n.Recv == nil || len(n.Recv.List) == 0 {
+		return "-", ""
+	}
+	return typeName(n.Recv.List[0].Type)
+}
+
+// resultType returns the name and type parameters of the type returned first by n, if it
+// is declared in the package, e.g. "Foo" for "func NewFoo() (*Foo, error)".
+func resultType(n *ast.FuncDecl) (name, typeParams string, ok bool) {
+	if n.Type.Results == nil || len(n.Type.Results.List) == 0 {
+		return "", "", false
+	}
+	name, typeParams = typeName(n.Type.Results.List[0].Type)
+	if !token.IsIdentifier(name) || types.Universe.Lookup(name) != nil || isTypeParam(n, name) {
+		// Types of other packages, predeclared and unnamed types and type parameters have no
+		// class.
+		return "", "", false
+	}
+	return name, typeParams, true
+}
+
+// isTypeParam returns whether name is one of the type parameters of n.
+func isTypeParam(n *ast.FuncDecl, name string) bool {
+	if n.Type.TypeParams == nil {
+		return false
+	}
+	for _, field := range n.Type.TypeParams.List {
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeName returns the name of a type without pointer and type arguments, and the type
+// arguments, e.g. "List" and "[T]" for "*List[T]".
+func typeName(typ ast.Expr) (name, typeParams string) {
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			return types.ExprString(t.X), "[" + types.ExprString(t.Index) + "]"
+		case *ast.IndexListExpr:
+			params := make([]string, 0, len(t.Indices))
+			for _, index := range t.Indices {
+				params = append(params, types.ExprString(index))
+			}
+			return types.ExprString(t.X), "[" + strings.Join(params, ", ") + "]"
+		default:
+			return types.ExprString(typ), ""
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestClassGroupings(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		decl     string
+		expected map[string]string
+	}{
+		{
+			decl: `func Func1() {}`,
+			expected: map[string]string{
+				"receiver": "-", "type": "-", "constructors": "-", "files": "func1.go", "functions": "Func1",
+			},
+		},
+		{
+			decl: `func (r *Type1) Func2a() {}`,
+			expected: map[string]string{
+				"receiver": "Type1", "type": "Type1", "constructors": "Type1", "files": "Type1",
+				"functions": "Type1.Func2a",
+			},
+		},
+		{
+			decl: `func NewType1() (*Type1, error) {}`,
+			expected: map[string]string{
+				"receiver": "-", "type": "-", "constructors": "Type1", "files": "func1.go", "functions": "NewType1",
+			},
+		},
+		{
+			decl: `func NewList[T any]() *List[T] {}`,
+			expected: map[string]string{
+				"receiver": "-", "type": "-", "constructors": "List", "files": "func1.go", "functions": "NewList",
+			},
+		},
+		{
+			decl: `func Max[T int | float64](a, b T) T {}`,
+			expected: map[string]string{
+				"receiver": "-", "type": "-", "constructors": "-", "files": "func1.go", "functions": "Max",
+			},
+		},
+		{
+			decl: `func Ptr[T any](v T) *T {}`,
+			expected: map[string]string{
+				"receiver": "-", "type": "-", "constructors": "-", "files": "func1.go", "functions": "Ptr",
+			},
+		},
+		{
+			decl: `func Parse() (int, error) {}`,
+			expected: map[string]string{
+				"receiver": "-", "type": "-", "constructors": "-", "files": "func1.go", "functions": "Parse",
+			},
+		},
+		{
+			decl: `func Open() io.Reader {}`,
+			expected: map[string]string{
+				"receiver": "-", "type": "-", "constructors": "-", "files": "func1.go", "functions": "Open",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.decl, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\n"+tc.decl, 0)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+			fn := parsed.Decls[0].(*ast.FuncDecl)
+			for grouping, expected := range tc.expected {
+				if name, _ := classGroupings[grouping](fn, "testdata/func1.go"); name != expected {
+					t.Errorf("expected class %s with grouping %s, got %s", expected, grouping, name)
+				}
+			}
+		})
+	}
+}
+
+func TestResultTypeParams(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\nfunc NewPair[K, V any]() Pair[K, V] {}", 0)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	name, typeParams, ok := resultType(parsed.Decls[0].(*ast.FuncDecl))
+	if !ok || name != "Pair" || typeParams != "[K, V]" {
+		t.Errorf("expected Pair and [K, V], got %s and %s (%t)", name, typeParams, ok)
+	}
+}
+
+func TestConvertClassGroupingDisplayNames(t *testing.T) {
+	t.Parallel()
+
+	for _, grouping := range slices.Sorted(maps.Keys(classGroupings)) {
+		t.Run(grouping, func(t *testing.T) {
+			t.Parallel()
+
+			// Functions are named after their receiver, whatever class they are grouped into.
+			expected := []string{"Func1", "Type1.Func2a", "Type1.Func2b", "Type1.Func2c"}
+			check := func(format string, functions []string) {
+				t.Helper()
+
+				slices.Sort(functions)
+				if functions = slices.Compact(functions); !slices.Equal(functions, expected) {
+					t.Errorf("expected functions %v in %s report, got %v", expected, format, functions)
+				}
+			}
+
+			out := convertTestdata(t, "testdata/testdata_set.txt", &Options{
+				ClassGrouping: grouping,
+				Format:        "crap-json",
+			})
+			var crap crapReport
+			if err := json.Unmarshal(out.Bytes(), &crap); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			var functions []string
+			for _, method := range crap.Methods {
+				functions = append(functions, method.Function)
+			}
+			check("crap-json", functions)
+
+			out = convertTestdata(t, "testdata/testdata_set.txt", &Options{
+				ClassGrouping: grouping,
+				Format:        "calls",
+			})
+			var calls callsReport
+			if err := json.Unmarshal(out.Bytes(), &calls); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			functions = nil
+			for _, function := range calls.Functions {
+				functions = append(functions, function.Function)
+			}
+			check("calls", functions)
+
+			out = convertTestdata(t, "testdata/testdata_set.txt", &Options{
+				ClassGrouping: grouping,
+				Format:        "paths",
+			})
+			var paths struct {
+				Packages []pathsPackage `json:"packages"`
+			}
+			if err := json.Unmarshal(out.Bytes(), &paths); err != nil {
+				t.Fatalf("failed to decode report: %v", err)
+			}
+			functions = nil
+			for _, method := range paths.Packages[0].Methods {
+				functions = append(functions, method.Function)
+			}
+			check("paths", functions)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"go/ast"
+	"slices"
+
+	"golang.org/x/tools/cover"
+)
+
+// funcLits returns the function literals in node that are not nested in another function
+// literal, in source order.
+func funcLits(node ast.Node) []*ast.FuncLit {
+	var lits []*ast.FuncLit
+	if node == nil {
+		return lits
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			lits = append(lits, lit)
+			return false
+		}
+		return true
+	})
+	return lits
+}
+
+// closureDecl turns a function literal into a declaration with the given name and
+// receiver, so that it can be reported like any other function. The name follows the
+// names the compiler gives closures, e.g. "handler.func1" or "Serve.func1.2".
+func closureDecl(recv *ast.FieldList, name string, lit *ast.FuncLit) *ast.FuncDecl {
+	return &ast.FuncDecl{Recv: recv, Name: ast.NewIdent(name), Type: lit.Type, Body: lit.Body}
+}
+
+// inClosure returns whether block b starts in the body of one of closures.
+func (v *fileVisitor) inClosure(b cover.ProfileBlock, closures []*ast.FuncLit) bool {
+	return slices.ContainsFunc(closures, func(lit *ast.FuncLit) bool {
+		start := v.fset.PositionFor(lit.Body.Lbrace, false)
+		end := v.fset.PositionFor(lit.Body.End(), false)
+		return (b.StartLine > start.Line || (b.StartLine == start.Line && b.StartCol >= start.Column)) &&
+			(b.StartLine < end.Line || (b.StartLine == end.Line && b.StartCol < end.Column))
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConvertClosures(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name     string
+		closures bool
+		expected map[string][]int
+	}{
+		{
+			name: "package level",
+			expected: map[string][]int{
+				"Handler.func1": {6, 7, 9},
+				"Outer":         {13, 14, 16, 17, 18, 20, 22},
+				"Twice":         {26, 27},
+				"glob.func1":    {31},
+				"glob.func2":    {35},
+			},
+		},
+		{
+			name:     "nested",
+			closures: true,
+			expected: map[string][]int{
+				"Handler.func1": {6, 7, 9},
+				"Outer":         {13, 16, 22},
+				"Outer.func1":   {14},
+				"Outer.func2":   {17, 20},
+				"Outer.func2.1": {18},
+				"Twice":         {26, 27},
+				"Twice.func1":   {26},
+				"glob.func1":    {31},
+				"glob.func2":    {35},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := decodeCoverage(t, convertTestdata(t, "testdata/testdata_closures.txt", &Options{
+				Ignore:   &Ignore{},
+				Closures: tc.closures,
+			}))
+
+			class := v.Packages[0].Classes[0]
+			methods := class.Methods
+			if len(methods) != len(tc.expected) {
+				t.Fatalf("expected %d methods, got %d", len(tc.expected), len(methods))
+			}
+			for _, m := range methods {
+				var lines []int
+				for _, line := range m.Lines {
+					lines = append(lines, line.Number)
+				}
+				if !slices.Equal(lines, tc.expected[m.Name]) {
+					t.Errorf("expected lines %v for %s, got %v", tc.expected[m.Name], m.Name, lines)
+				}
+			}
+			// The line declaring the closure in Twice is listed once, with the counts of both
+			// functions merged.
+			var lines []int
+			for _, line := range class.Lines {
+				lines = append(lines, line.Number)
+				if line.Number == 26 && line.Hits != 1 {
+					t.Errorf("expected 1 hit of line 26, got %d", line.Hits)
+				}
+			}
+			expected := []int{6, 7, 9, 13, 14, 16, 17, 18, 20, 22, 26, 27, 31, 35}
+			if !slices.Equal(lines, expected) {
+				t.Errorf("expected class lines %v, got %v", expected, lines)
+			}
+			if int(v.LinesValid) != 14 {
+				t.Errorf("expected 14 valid lines, got %d", v.LinesValid)
+			}
+		})
+	}
+}
@@ -1,22 +1,36 @@
 package main
 
 import (
+	"bytes"
 	"encoding/xml"
+	"fmt"
+	"math"
+
+	"golang.org/x/tools/cover"
 )
 
 type Coverage struct {
-	XMLName         xml.Name   `xml:"coverage"`
-	LineRate        float32    `xml:"line-rate,attr"`
-	BranchRate      float32    `xml:"branch-rate,attr"`
-	Version         string     `xml:"version,attr"`
-	Timestamp       int64      `xml:"timestamp,attr"`
-	LinesCovered    int64      `xml:"lines-covered,attr"`
-	LinesValid      int64      `xml:"lines-valid,attr"`
-	BranchesCovered int64      `xml:"branches-covered,attr"`
-	BranchesValid   int64      `xml:"branches-valid,attr"`
-	Complexity      float32    `xml:"complexity,attr"`
-	Sources         []*Source  `xml:"sources>source"`
-	Packages        []*Package `xml:"packages>package"`
+	XMLName         xml.Name `xml:"coverage"`
+	LineRate        float32  `xml:"line-rate,attr"`
+	BranchRate      float32  `xml:"branch-rate,attr"`
+	Version         string   `xml:"version,attr"`
+	Timestamp       int64    `xml:"timestamp,attr"`
+	LinesCovered    int64    `xml:"lines-covered,attr"`
+	LinesValid      int64    `xml:"lines-valid,attr"`
+	BranchesCovered int64    `xml:"branches-covered,attr"`
+	BranchesValid   int64    `xml:"branches-valid,attr"`
+	Complexity      float32  `xml:"complexity,attr"`
+	// StatementsCovered and StatementsValid are the statement counts the line rate is
+	// computed from with Options.StatementRates. They are not part of the Cobertura format
+	// and only set in that case.
+	StatementsCovered int64      `xml:"statements-covered,attr,omitempty"`
+	StatementsValid   int64      `xml:"statements-valid,attr,omitempty"`
+	Sources           []*Source  `xml:"sources>source"`
+	Packages          []*Package `xml:"packages>package"`
+
+	// Files holds the coverage of every source file in the report. It is not part
+	// of the Cobertura format, but used for the other kinds of reports.
+	Files []*File `xml:"-"`
 }
 
 type Source struct {
@@ -29,6 +43,15 @@ type Package struct {
 	BranchRate float32  `xml:"branch-rate,attr"`
 	Complexity float32  `xml:"complexity,attr"`
 	Classes    []*Class `xml:"classes>class"`
+
+	// classes are the last class of every type of the package by name, if classes are
+	// grouped by type.
+	classes map[string]*Class
+	// methodNames counts how often every method name was used in the package.
+	methodNames map[string]int
+	// funcLits counts the function literals at package level by the name they are numbered
+	// after, as the compiler numbers them across all files of the package.
+	funcLits map[string]int
 }
 
 type Class struct {
@@ -47,12 +70,116 @@ type Method struct {
 	LineRate   float32 `xml:"line-rate,attr"`
 	BranchRate float32 `xml:"branch-rate,attr"`
 	Complexity float32 `xml:"complexity,attr"`
-	Lines      Lines   `xml:"lines>line"`
+	// Calls is how often the function was entered. It is not part of the Cobertura format
+	// and only set if requested with Options.CallAttributes.
+	Calls *int64 `xml:"calls,attr,omitempty"`
+	Lines Lines  `xml:"lines>line"`
+
+	// Location of the function in its source file. It is not part of the Cobertura
+	// format, but used for the other kinds of reports.
+	Filename  string `xml:"-"`
+	StartLine int    `xml:"-"`
+	EndLine   int    `xml:"-"`
+
+	blocks   []cover.ProfileBlock // profile blocks of the function
+	exported bool                 // whether the function is part of the API of its package
+	recv     string               // name of the receiver type of the function, if any
+}
+
+// NumCalls returns how often the function was entered, which is the count of its first
+// block. Profiles in set mode only tell whether it was entered at all.
+func (method Method) NumCalls() int64 {
+	if len(method.blocks) == 0 {
+		return 0
+	}
+	return int64(method.blocks[0].Count)
+}
+
+// CRAP returns the Change Risk Anti-Patterns score of the method, which combines its
+// cyclomatic complexity comp with its line rate cov as comp² * (1 - cov)³ + comp.
+func (method Method) CRAP() float64 {
+	comp, cov := float64(method.Complexity), float64(method.LineRate)
+	return comp*comp*math.Pow(1-cov, 3) + comp
+}
+
+// File is a source file of the report together with the coverage of its lines.
+type File struct {
+	Name    string // name of the file relative to the module root
+	Path    string // absolute path of the file
+	Package string // name of the package the file belongs to
+	Lines   Lines  // lines covered by the profile, in source order
+	data    []byte // content of the file
+
+	methods []*Method // methods declared in the file
+}
+
+// StatementRate returns a float32 from 0.0 to 1.0 representing what fraction of
+// statements have hits
+func (file *File) StatementRate() float32 {
+	return float32(coverageRate(file.NumStatementsWithHits(), file.NumStatements()))
+}
+
+// NumStatements returns the number of statements
+func (file *File) NumStatements() (numStatements int64) {
+	for _, method := range file.methods {
+		numStatements += method.NumStatements()
+	}
+	return numStatements
+}
+
+// NumStatementsWithHits returns the number of statements with a hit count > 0
+func (file *File) NumStatementsWithHits() (numStatementsWithHits int64) {
+	for _, method := range file.methods {
+		numStatementsWithHits += method.NumStatementsWithHits()
+	}
+	return numStatementsWithHits
+}
+
+// sourceLines returns the lines of the file content without line endings.
+func (file *File) sourceLines() [][]byte {
+	src := bytes.TrimSuffix(file.data, []byte("\n"))
+	lines := bytes.Split(src, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimSuffix(line, []byte("\r"))
+	}
+	return lines
 }
 
 type Line struct {
-	Number int   `xml:"number,attr"`
-	Hits   int64 `xml:"hits,attr"`
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr,omitempty"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+
+	// Number of branch outcomes on the line and how many of them were taken.
+	branches        int64
+	branchesCovered int64
+
+	// The counts of the blocks on the line, merged into Hits.
+	counts []int64
+
+	// The path of execution the code on the line belongs to.
+	path pathKind
+}
+
+// addBranches records branch outcomes on the line and updates the condition coverage.
+func (line *Line) addBranches(branches, covered int64) {
+	line.branches += branches
+	line.branchesCovered += covered
+	line.Branch = true
+	line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)",
+		line.branchesCovered*100/line.branches, line.branchesCovered, line.branches)
+}
+
+// markPartial marks a line on which only some of the blocks were executed as partially
+// covered, the way Cobertura marks conditions. Lines with branches keep their condition
+// coverage, and the blocks do not count as branches.
+func (line *Line) markPartial(blocks, executed int64) {
+	if line.branches > 0 {
+		return
+	}
+	line.Branch = true
+	line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)", executed*100/blocks, executed, blocks)
 }
 
 // Lines is a slice of Line pointers, with some convenience methods
@@ -61,7 +188,7 @@ type Lines []*Line
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits
 func (lines Lines) HitRate() (hitRate float32) {
-	return float32(lines.NumLinesWithHits()) / float32(len(lines))
+	return float32(coverageRate(lines.NumLinesWithHits(), lines.NumLines()))
 }
 
 // NumLines returns the number of lines
@@ -79,26 +206,32 @@ func (lines Lines) NumLinesWithHits() (numLinesWithHits int64) {
 	return numLinesWithHits
 }
 
-// AddOrUpdateLine adds a line if it is a different line than the last line recorded.
-// If it's the same line as the last line recorded then we update the hits down
-// if the new hits is less; otherwise just leave it as-is
-func (lines *Lines) AddOrUpdateLine(lineNumber int, hits int64) {
-	if len(*lines) > 0 {
-		lastLine := (*lines)[len(*lines)-1]
-		if lineNumber == lastLine.Number {
-			if hits < lastLine.Hits {
-				lastLine.Hits = hits
-			}
-			return
-		}
+// NumBranches returns the number of branch outcomes on all lines
+func (lines Lines) NumBranches() (numBranches int64) {
+	for _, line := range lines {
+		numBranches += line.branches
 	}
-	*lines = append(*lines, &Line{Number: lineNumber, Hits: hits})
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes on all lines that were taken
+func (lines Lines) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, line := range lines {
+		numBranchesCovered += line.branchesCovered
+	}
+	return numBranchesCovered
+}
+
+// BranchHitRate returns a float32 from 0.0 to 1.0 representing what fraction of branch
+// outcomes were taken. Like Cobertura it is 1.0 if there are no branches at all.
+func (lines Lines) BranchHitRate() float32 {
+	return branchRate(lines.NumBranchesCovered(), lines.NumBranches())
 }
 
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits
 func (method Method) HitRate() float32 {
-	return method.Lines.HitRate()
+	return method.rate(method.NumLinesWithHits(), method.NumLines())
 }
 
 // NumLines returns the number of lines
@@ -111,32 +244,138 @@ func (method Method) NumLinesWithHits() int64 {
 	return method.Lines.NumLinesWithHits()
 }
 
+// StatementRate returns a float32 from 0.0 to 1.0 representing what fraction of
+// statements have hits, like go tool cover does
+func (method Method) StatementRate() float32 {
+	return method.rate(method.NumStatementsWithHits(), method.NumStatements())
+}
+
+// rate returns the fraction of covered lines or statements of the method. Functions without
+// any, like empty ones, are covered once they are called.
+func (method Method) rate(covered, valid int64) float32 {
+	if valid == 0 && method.NumCalls() > 0 {
+		return 1
+	}
+	return float32(coverageRate(covered, valid))
+}
+
+// NumStatements returns the number of statements
+func (method Method) NumStatements() (numStatements int64) {
+	for _, b := range method.blocks {
+		numStatements += int64(b.NumStmt)
+	}
+	return numStatements
+}
+
+// NumStatementsWithHits returns the number of statements with a hit count > 0
+func (method Method) NumStatementsWithHits() (numStatementsWithHits int64) {
+	for _, b := range method.blocks {
+		if b.Count > 0 {
+			numStatementsWithHits += int64(b.NumStmt)
+		}
+	}
+	return numStatementsWithHits
+}
+
+// BranchHitRate returns a float32 from 0.0 to 1.0 representing what fraction of branch
+// outcomes were taken
+func (method Method) BranchHitRate() float32 {
+	return method.Lines.BranchHitRate()
+}
+
+// NumBranches returns the number of branch outcomes
+func (method Method) NumBranches() int64 {
+	return method.Lines.NumBranches()
+}
+
+// NumBranchesCovered returns the number of branch outcomes that were taken
+func (method Method) NumBranchesCovered() int64 {
+	return method.Lines.NumBranchesCovered()
+}
+
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits
 func (class Class) HitRate() float32 {
-	return float32(class.NumLinesWithHits()) / float32(class.NumLines())
+	return float32(coverageRate(class.NumLinesWithHits(), class.NumLines()))
 }
 
-// NumLines returns the number of lines
-func (class Class) NumLines() (numLines int64) {
+// NumLines returns the number of lines. Lines with code of several methods, like a line
+// declaring a closure, count once.
+func (class Class) NumLines() int64 {
+	return class.Lines.NumLines()
+}
+
+// NumLinesWithHits returns the number of lines with a hit count > 0
+func (class Class) NumLinesWithHits() int64 {
+	return class.Lines.NumLinesWithHits()
+}
+
+// StatementRate returns a float32 from 0.0 to 1.0 representing what fraction of
+// statements have hits
+func (class Class) StatementRate() float32 {
+	return float32(coverageRate(class.NumStatementsWithHits(), class.NumStatements()))
+}
+
+// NumStatements returns the number of statements
+func (class Class) NumStatements() (numStatements int64) {
 	for _, method := range class.Methods {
-		numLines += method.NumLines()
+		numStatements += method.NumStatements()
 	}
-	return numLines
+	return numStatements
 }
 
-// NumLinesWithHits returns the number of lines with a hit count > 0
-func (class Class) NumLinesWithHits() (numLinesWithHits int64) {
+// NumStatementsWithHits returns the number of statements with a hit count > 0
+func (class Class) NumStatementsWithHits() (numStatementsWithHits int64) {
 	for _, method := range class.Methods {
-		numLinesWithHits += method.NumLinesWithHits()
+		numStatementsWithHits += method.NumStatementsWithHits()
 	}
-	return numLinesWithHits
+	return numStatementsWithHits
+}
+
+// BranchHitRate returns a float32 from 0.0 to 1.0 representing what fraction of branch
+// outcomes were taken
+func (class Class) BranchHitRate() float32 {
+	return branchRate(class.NumBranchesCovered(), class.NumBranches())
+}
+
+// NumBranches returns the number of branch outcomes
+func (class Class) NumBranches() (numBranches int64) {
+	for _, method := range class.Methods {
+		numBranches += method.NumBranches()
+	}
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes that were taken
+func (class Class) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, method := range class.Methods {
+		numBranchesCovered += method.NumBranchesCovered()
+	}
+	return numBranchesCovered
+}
+
+// AverageComplexity returns the average cyclomatic complexity of the methods
+func (class Class) AverageComplexity() float32 {
+	return averageComplexity(class.TotalComplexity(), class.NumMethods())
+}
+
+// TotalComplexity returns the sum of the cyclomatic complexity of the methods
+func (class Class) TotalComplexity() (complexity float32) {
+	for _, method := range class.Methods {
+		complexity += method.Complexity
+	}
+	return complexity
+}
+
+// NumMethods returns the number of methods
+func (class Class) NumMethods() int64 {
+	return int64(len(class.Methods))
 }
 
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits
 func (pkg Package) HitRate() float32 {
-	return float32(pkg.NumLinesWithHits()) / float32(pkg.NumLines())
+	return float32(coverageRate(pkg.NumLinesWithHits(), pkg.NumLines()))
 }
 
 // NumLines returns the number of lines
@@ -155,10 +394,75 @@ func (pkg Package) NumLinesWithHits() (numLinesWithHits int64) {
 	return numLinesWithHits
 }
 
+// StatementRate returns a float32 from 0.0 to 1.0 representing what fraction of
+// statements have hits
+func (pkg Package) StatementRate() float32 {
+	return float32(coverageRate(pkg.NumStatementsWithHits(), pkg.NumStatements()))
+}
+
+// NumStatements returns the number of statements
+func (pkg Package) NumStatements() (numStatements int64) {
+	for _, class := range pkg.Classes {
+		numStatements += class.NumStatements()
+	}
+	return numStatements
+}
+
+// NumStatementsWithHits returns the number of statements with a hit count > 0
+func (pkg Package) NumStatementsWithHits() (numStatementsWithHits int64) {
+	for _, class := range pkg.Classes {
+		numStatementsWithHits += class.NumStatementsWithHits()
+	}
+	return numStatementsWithHits
+}
+
+// BranchHitRate returns a float32 from 0.0 to 1.0 representing what fraction of branch
+// outcomes were taken
+func (pkg Package) BranchHitRate() float32 {
+	return branchRate(pkg.NumBranchesCovered(), pkg.NumBranches())
+}
+
+// NumBranches returns the number of branch outcomes
+func (pkg Package) NumBranches() (numBranches int64) {
+	for _, class := range pkg.Classes {
+		numBranches += class.NumBranches()
+	}
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes that were taken
+func (pkg Package) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, class := range pkg.Classes {
+		numBranchesCovered += class.NumBranchesCovered()
+	}
+	return numBranchesCovered
+}
+
+// AverageComplexity returns the average cyclomatic complexity of the methods
+func (pkg Package) AverageComplexity() float32 {
+	return averageComplexity(pkg.TotalComplexity(), pkg.NumMethods())
+}
+
+// TotalComplexity returns the sum of the cyclomatic complexity of the methods
+func (pkg Package) TotalComplexity() (complexity float32) {
+	for _, class := range pkg.Classes {
+		complexity += class.TotalComplexity()
+	}
+	return complexity
+}
+
+// NumMethods returns the number of methods
+func (pkg Package) NumMethods() (numMethods int64) {
+	for _, class := range pkg.Classes {
+		numMethods += class.NumMethods()
+	}
+	return numMethods
+}
+
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits
 func (cov Coverage) HitRate() float32 {
-	return float32(cov.NumLinesWithHits()) / float32(cov.NumLines())
+	return float32(coverageRate(cov.NumLinesWithHits(), cov.NumLines()))
 }
 
 // NumLines returns the number of lines
@@ -176,3 +480,91 @@ func (cov Coverage) NumLinesWithHits() (numLinesWithHits int64) {
 	}
 	return numLinesWithHits
 }
+
+// StatementRate returns a float32 from 0.0 to 1.0 representing what fraction of
+// statements have hits
+func (cov Coverage) StatementRate() float32 {
+	return float32(coverageRate(cov.NumStatementsWithHits(), cov.NumStatements()))
+}
+
+// NumStatements returns the number of statements
+func (cov Coverage) NumStatements() (numStatements int64) {
+	for _, pkg := range cov.Packages {
+		numStatements += pkg.NumStatements()
+	}
+	return numStatements
+}
+
+// NumStatementsWithHits returns the number of statements with a hit count > 0
+func (cov Coverage) NumStatementsWithHits() (numStatementsWithHits int64) {
+	for _, pkg := range cov.Packages {
+		numStatementsWithHits += pkg.NumStatementsWithHits()
+	}
+	return numStatementsWithHits
+}
+
+// BranchHitRate returns a float32 from 0.0 to 1.0 representing what fraction of branch
+// outcomes were taken
+func (cov Coverage) BranchHitRate() float32 {
+	return branchRate(cov.NumBranchesCovered(), cov.NumBranches())
+}
+
+// NumBranches returns the number of branch outcomes
+func (cov Coverage) NumBranches() (numBranches int64) {
+	for _, pkg := range cov.Packages {
+		numBranches += pkg.NumBranches()
+	}
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes that were taken
+func (cov Coverage) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, pkg := range cov.Packages {
+		numBranchesCovered += pkg.NumBranchesCovered()
+	}
+	return numBranchesCovered
+}
+
+// AverageComplexity returns the average cyclomatic complexity of the methods
+func (cov Coverage) AverageComplexity() float32 {
+	return averageComplexity(cov.TotalComplexity(), cov.NumMethods())
+}
+
+// TotalComplexity returns the sum of the cyclomatic complexity of the methods
+func (cov Coverage) TotalComplexity() (complexity float32) {
+	for _, pkg := range cov.Packages {
+		complexity += pkg.TotalComplexity()
+	}
+	return complexity
+}
+
+// NumMethods returns the number of methods
+func (cov Coverage) NumMethods() (numMethods int64) {
+	for _, pkg := range cov.Packages {
+		numMethods += pkg.NumMethods()
+	}
+	return numMethods
+}
+
+// coverageRate returns the fraction of covered lines or statements, or 0 if there are none
+// at all.
+func coverageRate(covered, valid int64) float64 {
+	if valid == 0 {
+		return 0
+	}
+	return float64(covered) / float64(valid)
+}
+
+func branchRate(covered, valid int64) float32 {
+	if valid == 0 {
+		return 1
+	}
+	return float32(covered) / float32(valid)
+}
+
+func averageComplexity(total float32, numMethods int64) float32 {
+	if numMethods == 0 {
+		return 0
+	}
+	return total / float32(numMethods)
+}
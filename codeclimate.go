@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type codeClimateReport struct {
+	SourceFiles     []codeClimateSourceFile `json:"source_files"`
+	LineCounts      codeClimateLineCounts   `json:"line_counts"`
+	CoveredPercent  float64                 `json:"covered_percent"`
+	CoveredStrength float64                 `json:"covered_strength"`
+}
+
+type codeClimateSourceFile struct {
+	Name            string                `json:"name"`
+	BlobID          string                `json:"blob_id"`
+	Coverage        string                `json:"coverage"`
+	CoveredPercent  float64               `json:"covered_percent"`
+	CoveredStrength float64               `json:"covered_strength"`
+	LineCounts      codeClimateLineCounts `json:"line_counts"`
+}
+
+type codeClimateLineCounts struct {
+	Total   int64 `json:"total"`
+	Covered int64 `json:"covered"`
+	Missed  int64 `json:"missed"`
+}
+
+// writeCodeClimate writes the coverage of the report in the JSON format produced by
+// cc-test-reporter format-coverage, which can be uploaded to Code Climate.
+func writeCodeClimate(out io.Writer, coverage *Coverage, _ *Options) error {
+	report := codeClimateReport{SourceFiles: []codeClimateSourceFile{}}
+	var hits int64
+	for _, file := range coverage.Files {
+		sourceFile := codeClimateFile(file)
+		report.SourceFiles = append(report.SourceFiles, sourceFile)
+		report.LineCounts.Total += sourceFile.LineCounts.Total
+		report.LineCounts.Covered += sourceFile.LineCounts.Covered
+		report.LineCounts.Missed += sourceFile.LineCounts.Missed
+		for _, line := range file.Lines {
+			hits += line.Hits
+		}
+	}
+	report.CoveredPercent = coverageRate(report.LineCounts.Covered, report.LineCounts.Total) * 100
+	report.CoveredStrength = coverageRate(hits, report.LineCounts.Total)
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("encode Code Climate report: %w", err)
+	}
+	return nil
+}
+
+func codeClimateFile(file *File) codeClimateSourceFile {
+	hits := make(map[int]int64, len(file.Lines))
+	var strength int64
+	for _, line := range file.Lines {
+		hits[line.Number] = line.Hits
+		strength += line.Hits
+	}
+
+	// Code Climate expects the coverage as JSON array encoded in a string, with the
+	// hits of every line of the file or null for lines that are not executable.
+	lines := make([]string, len(file.sourceLines()))
+	for i := range lines {
+		lines[i] = "null"
+		if n, ok := hits[i+1]; ok {
+			lines[i] = strconv.FormatInt(n, 10)
+		}
+	}
+
+	covered := file.Lines.NumLinesWithHits()
+	total := file.Lines.NumLines()
+	return codeClimateSourceFile{
+		Name:            file.Name,
+		BlobID:          gitBlobID(file.data),
+		Coverage:        "[" + strings.Join(lines, ",") + "]",
+		CoveredPercent:  coverageRate(covered, total) * 100,
+		CoveredStrength: coverageRate(strength, total),
+		LineCounts: codeClimateLineCounts{
+			Total:   total,
+			Covered: covered,
+			Missed:  total - covered,
+		},
+	}
+}
+
+// gitBlobID returns the id git assigns to a blob with the given content.
+func gitBlobID(data []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
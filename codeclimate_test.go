@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestGitBlobID(t *testing.T) {
+	t.Parallel()
+
+	// echo 'test content' | git hash-object --stdin
+	const expected = "d670460b4b4aece5915caf5c68d12f560a9fe3e4"
+	if id := gitBlobID([]byte("test content\n")); id != expected {
+		t.Errorf("expected blob id %s, got %s", expected, id)
+	}
+}
+
+func TestConvertCodeClimate(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_set.txt", &Options{Format: "codeclimate"})
+
+	var report codeClimateReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+
+	expectedCounts := codeClimateLineCounts{Total: 4, Covered: 3, Missed: 1}
+	if report.LineCounts != expectedCounts {
+		t.Errorf("expected line counts %+v, got %+v", expectedCounts, report.LineCounts)
+	}
+	if len(report.SourceFiles) != 2 {
+		t.Fatalf("expected 2 source files, got %d", len(report.SourceFiles))
+	}
+
+	file := report.SourceFiles[0]
+	if file.Name != "testdata/func1.go" {
+		t.Errorf("expected file testdata/func1.go, got %s", file.Name)
+	}
+	if file.Coverage != "[null,null,null,null,null,1,0,null,null]" {
+		t.Errorf("unexpected coverage %s", file.Coverage)
+	}
+	if file.CoveredPercent != 50 {
+		t.Errorf("expected 50%% covered, got %f", file.CoveredPercent)
+	}
+	data, err := os.ReadFile("testdata/func1.go")
+	if err != nil {
+		t.Fatalf("failed to read func1.go: %v", err)
+	}
+	if file.BlobID != gitBlobID(data) {
+		t.Errorf("expected blob id %s, got %s", gitBlobID(data), file.BlobID)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+)
+
+// cyclomaticComplexity returns the McCabe cyclomatic complexity of a function: one plus
+// the number of decisions in its body. Decisions are if and for statements, case and comm
+// clauses other than default, and the && and || operators. Function literals count
+// towards the function they are declared in, unless they are one of closures, which are
+// reported on their own.
+func cyclomaticComplexity(body *ast.BlockStmt, closures []*ast.FuncLit) int {
+	complexity := 1
+	if body == nil {
+		return complexity
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return !slices.Contains(closures, n)
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
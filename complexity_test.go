@@ -0,0 +1,65 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestCyclomaticComplexity(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"empty", ``, 1},
+		{"if", `if a { return }`, 2},
+		{"if else", `if a { return } else if b { return } else { return }`, 3},
+		{"conditions", `if a && b || c { return }`, 4},
+		{"for", `for i := 0; i < 10; i++ {}; for range 10 {}`, 3},
+		{"switch", `switch { case a: case b, c: default: }`, 3},
+		{"select", `select { case <-ch: case ch <- 1: default: }`, 3},
+		{"closure", `f := func() { if a { return } }; f()`, 2},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			src := "package p\nfunc f() {\n" + tc.body + "\n}\n"
+			parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+			fn := parsed.Decls[0].(*ast.FuncDecl)
+			if complexity := cyclomaticComplexity(fn.Body, nil); complexity != tc.expected {
+				t.Errorf("expected complexity %d, got %d", tc.expected, complexity)
+			}
+		})
+	}
+}
+
+func TestConvertComplexity(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_branches.txt", &Options{Ignore: &Ignore{}})
+
+	v := decodeCoverage(t, out)
+
+	if v.Complexity != 2.5 {
+		t.Errorf("expected average complexity 2.5, got %f", v.Complexity)
+	}
+	if v.Packages[0].Complexity != 2.5 || v.Packages[0].Classes[0].Complexity != 2.5 {
+		t.Errorf("expected package and class complexity 2.5, got %f and %f",
+			v.Packages[0].Complexity, v.Packages[0].Classes[0].Complexity)
+	}
+	expected := map[string]float32{"If": 3, "IfElse": 3, "Switch": 3, "For": 3, "Select": 2, "Straight": 1}
+	for _, m := range v.Packages[0].Classes[0].Methods {
+		if m.Complexity != expected[m.Name] {
+			t.Errorf("expected complexity %f for %s, got %f", expected[m.Name], m.Name, m.Complexity)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+type crapReport struct {
+	Methods []crapMethod `json:"methods"`
+}
+
+type crapMethod struct {
+	Package    string  `json:"package"`
+	Class      string  `json:"class"`
+	Function   string  `json:"function"`
+	File       string  `json:"file"`
+	StartLine  int     `json:"start_line"`
+	Complexity float32 `json:"complexity"`
+	LineRate   float32 `json:"line_rate"`
+	CRAP       float64 `json:"crap"`
+}
+
+// collectCRAP returns all functions of the report ranked by their CRAP score, riskiest
+// first.
+func collectCRAP(coverage *Coverage, opts *Options) crapReport {
+	report := crapReport{Methods: []crapMethod{}}
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				report.Methods = append(report.Methods, crapMethod{
+					Package:    pkg.Name,
+					Class:      class.Name,
+					Function:   methodDisplayName(method, opts),
+					File:       method.Filename,
+					StartLine:  method.StartLine,
+					Complexity: method.Complexity,
+					LineRate:   method.LineRate,
+					CRAP:       method.CRAP(),
+				})
+			}
+		}
+	}
+
+	// Sort stable by score only, so functions with the same score stay in the order of the
+	// report.
+	slices.SortStableFunc(report.Methods, func(a, b crapMethod) int {
+		return cmp.Compare(b.CRAP, a.CRAP)
+	})
+	return report
+}
+
+// writeCRAP writes the functions ranked by their CRAP score as aligned text columns.
+func writeCRAP(out io.Writer, coverage *Coverage, opts *Options) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CRAP\tCOMPLEXITY\tCOVERAGE\tFUNCTION\tLOCATION")
+	for _, method := range collectCRAP(coverage, opts).Methods {
+		fmt.Fprintf(w, "%.1f\t%.0f\t%.1f%%\t%s\t%s:%d\n", method.CRAP, method.Complexity, method.LineRate*100,
+			method.Function, method.File, method.StartLine)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write CRAP report: %w", err)
+	}
+	return nil
+}
+
+// writeCRAPJSON writes the functions ranked by their CRAP score as JSON.
+func writeCRAPJSON(out io.Writer, coverage *Coverage, opts *Options) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collectCRAP(coverage, opts)); err != nil {
+		return fmt.Errorf("encode CRAP report: %w", err)
+	}
+	return nil
+}
+
+// writeCRAPMarkdown writes the functions ranked by their CRAP score as Markdown table, e.g.
+// for a pull request comment or a job summary.
+func writeCRAPMarkdown(out io.Writer, coverage *Coverage, opts *Options) error {
+	var b strings.Builder
+	b.WriteString("| CRAP | Complexity | Coverage | Function | Location |\n")
+	b.WriteString("| ---: | ---: | ---: | --- | --- |\n")
+	for _, method := range collectCRAP(coverage, opts).Methods {
+		fmt.Fprintf(&b, "| %.1f | %.0f | %.1f%% | `%s` | `%s:%d` |\n", method.CRAP, method.Complexity,
+			method.LineRate*100, method.Function, method.File, method.StartLine)
+	}
+	if _, err := io.WriteString(out, b.String()); err != nil {
+		return fmt.Errorf("write CRAP report: %w", err)
+	}
+	return nil
+}
+
+// checkCRAPThreshold returns an error naming the functions with a CRAP score above
+// opts.CRAPThreshold.
+func checkCRAPThreshold(coverage *Coverage, opts *Options) error {
+	if opts.CRAPThreshold <= 0 {
+		return nil
+	}
+	var failed []string
+	for _, method := range collectCRAP(coverage, opts).Methods {
+		if method.CRAP > opts.CRAPThreshold {
+			failed = append(failed, fmt.Sprintf("%s (%.1f)", method.Function, method.CRAP))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("CRAP score above the threshold of %.1f for %s",
+			opts.CRAPThreshold, strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMethodCRAP(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name     string
+		method   Method
+		expected float64
+	}{
+		{
+			name:     "uncovered",
+			method:   Method{Complexity: 3, Lines: Lines{{Number: 1}}},
+			expected: 12,
+		},
+		{
+			name:     "partially covered",
+			method:   Method{Complexity: 3, LineRate: 0.5, Lines: Lines{{Number: 1, Hits: 1}, {Number: 2}}},
+			expected: 4.125,
+		},
+		{
+			name:     "covered",
+			method:   Method{Complexity: 3, LineRate: 1, Lines: Lines{{Number: 1, Hits: 1}}},
+			expected: 3,
+		},
+		{
+			name:     "no lines called",
+			method:   Method{Complexity: 1, LineRate: 1},
+			expected: 1,
+		},
+		{
+			name:     "no lines not called",
+			method:   Method{Complexity: 1},
+			expected: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tc.method.CRAP(); math.Abs(got-tc.expected) > 1e-6 {
+				t.Errorf("expected CRAP score %f, got %f", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestConvertCRAP(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_branches.txt", &Options{Ignore: &Ignore{}, Format: "crap-json"})
+
+	var report crapReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+
+	var functions []string
+	for _, method := range report.Methods {
+		functions = append(functions, method.Function)
+	}
+	expected := []string{"IfElse", "Switch", "If", "For", "Select", "Straight"}
+	if !slices.Equal(functions, expected) {
+		t.Errorf("expected functions ranked %v, got %v", expected, functions)
+	}
+	riskiest := report.Methods[0]
+	if riskiest.Complexity != 3 || math.Abs(riskiest.CRAP-4.944) > 1e-3 {
+		t.Errorf("expected IfElse with complexity 3 and CRAP score 4.944, got %+v", riskiest)
+	}
+}
+
+func TestConvertCRAPText(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		format string
+		header string
+		row    string
+	}{
+		{
+			format: "crap",
+			header: "CRAP  COMPLEXITY  COVERAGE  FUNCTION  LOCATION",
+			row:    "4.9   3           40.0%     IfElse    testdata/branches.go:12",
+		},
+		{
+			format: "crap-md",
+			header: "| CRAP | Complexity | Coverage | Function | Location |",
+			row:    "| 4.9 | 3 | 40.0% | `IfElse` | `testdata/branches.go:12` |",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			out := convertTestdata(t, "testdata/testdata_branches.txt", &Options{Ignore: &Ignore{}, Format: tc.format})
+
+			lines := strings.Split(out.String(), "\n")
+			if lines[0] != tc.header {
+				t.Errorf("expected header %q, got %q", tc.header, lines[0])
+			}
+			if !slices.Contains(lines, tc.row) {
+				t.Errorf("expected row %q in:\n%s", tc.row, out)
+			}
+		})
+	}
+}
+
+func TestConvertCRAPThreshold(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name      string
+		threshold float64
+		expected  string
+	}{
+		{name: "disabled", threshold: 0},
+		{name: "passed", threshold: 5},
+		{
+			name:      "failed",
+			threshold: 3,
+			expected:  "CRAP score above the threshold of 3.0 for IfElse (4.9), Switch (3.1)",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := convertTestdataErr(t, "testdata/testdata_branches.txt", &Options{
+				Ignore:        &Ignore{},
+				CRAPThreshold: tc.threshold,
+			})
+			switch {
+			case tc.expected == "" && err != nil:
+				t.Errorf("expected threshold %.1f to pass, got: %v", tc.threshold, err)
+			case tc.expected != "" && (err == nil || err.Error() != tc.expected):
+				t.Errorf("expected error %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
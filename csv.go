@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// writeCSV writes the coverage of the report as CSV with one row for every method, or
+// with one row for every file if opts.CSVPerFile is set.
+func writeCSV(out io.Writer, coverage *Coverage, opts *Options) error {
+	w := csv.NewWriter(out)
+	if opts.CSVPerFile {
+		_ = w.Write([]string{
+			"package", "file", "lines_valid", "lines_covered", "line_rate",
+			"statements_valid", "statements_covered", "statement_rate",
+		})
+		for _, file := range coverage.Files {
+			_ = w.Write([]string{
+				file.Package,
+				file.Name,
+				strconv.FormatInt(file.Lines.NumLines(), 10),
+				strconv.FormatInt(file.Lines.NumLinesWithHits(), 10),
+				csvRate(file.Lines.HitRate()),
+				strconv.FormatInt(file.NumStatements(), 10),
+				strconv.FormatInt(file.NumStatementsWithHits(), 10),
+				csvRate(file.StatementRate()),
+			})
+		}
+	} else {
+		_ = w.Write([]string{
+			"package", "class", "file", "function", "start_line", "lines_valid", "lines_covered", "line_rate",
+			"statements_valid", "statements_covered", "statement_rate",
+		})
+		for _, pkg := range coverage.Packages {
+			for _, class := range pkg.Classes {
+				for _, method := range class.Methods {
+					_ = w.Write([]string{
+						pkg.Name,
+						class.Name,
+						method.Filename,
+						method.Name,
+						strconv.Itoa(method.StartLine),
+						strconv.FormatInt(method.NumLines(), 10),
+						strconv.FormatInt(method.NumLinesWithHits(), 10),
+						csvRate(method.HitRate()),
+						strconv.FormatInt(method.NumStatements(), 10),
+						strconv.FormatInt(method.NumStatementsWithHits(), 10),
+						csvRate(method.StatementRate()),
+					})
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("write CSV: %w", err)
+	}
+	return nil
+}
+
+func csvRate(rate float32) string {
+	return strconv.FormatFloat(float64(rate), 'f', 4, 32)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConvertCSV(t *testing.T) {
+	t.Parallel()
+
+	const pkg = "github.com/fasmat/gocover-cobertura/testdata"
+
+	tt := []struct {
+		name     string
+		perFile  bool
+		expected [][]string
+	}{
+		{
+			name: "methods",
+			expected: [][]string{
+				{
+					"package", "class", "file", "function", "start_line", "lines_valid", "lines_covered", "line_rate",
+					"statements_valid", "statements_covered", "statement_rate",
+				},
+				{pkg, "-", "testdata/func1.go", "Func1", "5", "2", "1", "0.5000", "2", "1", "0.5000"},
+				{pkg, "Type1", "testdata/func2.go", "Func2a", "8", "2", "2", "1.0000", "2", "2", "1.0000"},
+				{pkg, "Type1", "testdata/func2.go", "Func2b", "14", "0", "0", "0.0000", "0", "0", "0.0000"},
+				{pkg, "Type1", "testdata/func2.go", "Func2c", "17", "0", "0", "0.0000", "0", "0", "0.0000"},
+			},
+		},
+		{
+			name:    "files",
+			perFile: true,
+			expected: [][]string{
+				{
+					"package", "file", "lines_valid", "lines_covered", "line_rate",
+					"statements_valid", "statements_covered", "statement_rate",
+				},
+				{pkg, "testdata/func1.go", "2", "1", "0.5000", "2", "1", "0.5000"},
+				{pkg, "testdata/func2.go", "2", "2", "1.0000", "2", "2", "1.0000"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := convertTestdata(t, "testdata/testdata_set.txt", &Options{Format: "csv", CSVPerFile: tc.perFile})
+			records, err := csv.NewReader(out).ReadAll()
+			if err != nil {
+				t.Fatalf("failed to read CSV: %v", err)
+			}
+			if len(records) != len(tc.expected) {
+				t.Fatalf("expected %d rows, got %d: %v", len(tc.expected), len(records), records)
+			}
+			for i := range records {
+				if !slices.Equal(records[i], tc.expected[i]) {
+					t.Errorf("expected row %v, got %v", tc.expected[i], records[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConvertCSVEmptyFunctions(t *testing.T) {
+	t.Parallel()
+
+	// Only the empty functions Func2b and Func2c of func2.go, of which Func2b was called.
+	const profile = `mode: set
+github.com/fasmat/gocover-cobertura/testdata/func2.go:14.36,15.2 0 1
+github.com/fasmat/gocover-cobertura/testdata/func2.go:17.36,18.2 0 0
+`
+	out := new(bytes.Buffer)
+	err := convert(strings.NewReader(profile), out, &Options{Ignore: &Ignore{}, BuildTags: "testdata", Format: "csv"})
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	records, err := csv.NewReader(out).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+
+	// The rates agree with the line rates of the cobertura report.
+	expected := map[string][]string{
+		"Func2a": {"0.0000", "0.0000"},
+		"Func2b": {"1.0000", "1.0000"},
+		"Func2c": {"0.0000", "0.0000"},
+	}
+	for _, record := range records[1:] {
+		if rates := []string{record[7], record[10]}; !slices.Equal(rates, expected[record[3]]) {
+			t.Errorf("expected rates %v for %s, got %v", expected[record[3]], record[3], rates)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+
+	"golang.org/x/tools/cover"
+)
+
+// executableColumns returns the columns at which statements and expressions start in
+// body, by line. Lines without any, like blank lines, comments and lone closing braces,
+// have no entry. The bodies of closures are skipped, as they are reported on their own.
+func executableColumns(fset *token.FileSet, body *ast.BlockStmt, closures []*ast.FuncLit) map[int][]int {
+	columns := make(map[int][]int)
+	if body == nil {
+		return columns
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case nil, *ast.BlockStmt, *ast.EmptyStmt:
+			return true
+		case ast.Stmt, ast.Expr:
+			pos := fset.PositionFor(node.Pos(), false)
+			columns[pos.Line] = append(columns[pos.Line], pos.Column)
+			if lit, ok := n.(*ast.FuncLit); ok && slices.Contains(closures, lit) {
+				return false
+			}
+		}
+		return true
+	})
+	return columns
+}
+
+// executableIn returns whether a statement or expression on line starts within block b.
+func executableIn(b cover.ProfileBlock, line int, columns []int) bool {
+	for _, col := range columns {
+		if (line > b.StartLine || col >= b.StartCol) && (line < b.EndLine || col < b.EndCol) {
+			return true
+		}
+	}
+	return false
+}
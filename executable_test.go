@@ -0,0 +1,70 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/cover"
+)
+
+func TestExecutableColumns(t *testing.T) {
+	t.Parallel()
+
+	src := `package p
+
+func f(a int) {
+	// comment
+
+	if a > 0 {
+		g(a,
+			a+1)
+	} else {
+		return
+	}
+	s := []int{
+		1,
+	}
+	_ = s
+}
+`
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	fn := parsed.Decls[0].(*ast.FuncDecl)
+	columns := executableColumns(fset, fn.Body, nil)
+
+	lines := slices.Sorted(maps.Keys(columns))
+	expected := []int{6, 7, 8, 10, 12, 13, 15}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("expected executable lines %v, got %v", expected, lines)
+	}
+}
+
+func TestExecutableIn(t *testing.T) {
+	t.Parallel()
+
+	b := cover.ProfileBlock{StartLine: 6, StartCol: 12, EndLine: 9, EndCol: 3}
+	tt := []struct {
+		line     int
+		columns  []int
+		expected bool
+	}{
+		{6, []int{2, 5}, false},
+		{6, []int{2, 14}, true},
+		{7, []int{3}, true},
+		{9, []int{2}, true},
+		{9, []int{3}, false},
+		{8, nil, false},
+	}
+	for _, tc := range tt {
+		if executableIn(b, tc.line, tc.columns) != tc.expected {
+			t.Errorf("expected executableIn(%d, %v) to be %t", tc.line, tc.columns, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/cover"
+)
+
+type exportedReport struct {
+	Packages []exportedPackage `json:"packages"`
+}
+
+type exportedPackage struct {
+	Name      string             `json:"name"`
+	Exported  int                `json:"exported"`
+	Covered   int                `json:"covered"`
+	Rate      float64            `json:"rate"`
+	Uncovered []exportedFunction `json:"uncovered"`
+}
+
+type exportedFunction struct {
+	Class     string `json:"class"`
+	Function  string `json:"function"`
+	File      string `json:"file"`
+	StartLine int    `json:"start_line"`
+}
+
+// isExportedFunc returns whether n is part of the API of its package: an exported function,
+// or an exported method of an exported type. Closures are never exported.
+func isExportedFunc(n *ast.FuncDecl) bool {
+	if !token.IsIdentifier(n.Name.Name) || !ast.IsExported(n.Name.Name) {
+		return false
+	}
+	recv, _ := recvType(n)
+	return recv == "-" || ast.IsExported(recv)
+}
+
+// collectExported returns for every package how many of its exported functions have any
+// hits, and the exported functions that have none.
+func collectExported(coverage *Coverage, opts *Options) exportedReport {
+	report := exportedReport{Packages: []exportedPackage{}}
+	for _, pkg := range coverage.Packages {
+		p := exportedPackage{Name: pkg.Name, Uncovered: []exportedFunction{}}
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				if !method.exported {
+					continue
+				}
+				p.Exported++
+				if slices.ContainsFunc(method.blocks, func(b cover.ProfileBlock) bool { return b.Count > 0 }) {
+					p.Covered++
+					continue
+				}
+				p.Uncovered = append(p.Uncovered, exportedFunction{
+					Class:     class.Name,
+					Function:  methodDisplayName(method, opts),
+					File:      method.Filename,
+					StartLine: method.StartLine,
+				})
+			}
+		}
+		p.Rate = coverageRate(int64(p.Covered), int64(p.Exported))
+		report.Packages = append(report.Packages, p)
+	}
+	return report
+}
+
+// writeExported writes the share of exported functions with any hits of every package and
+// the exported functions without any as JSON.
+func writeExported(out io.Writer, coverage *Coverage, opts *Options) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collectExported(coverage, opts)); err != nil {
+		return fmt.Errorf("encode exported report: %w", err)
+	}
+	return nil
+}
+
+// checkExportedThreshold returns an error naming the packages in which less than
+// opts.ExportedThreshold percent of the exported functions have any hits. Packages
+// without exported functions always pass.
+func checkExportedThreshold(coverage *Coverage, opts *Options) error {
+	if opts.ExportedThreshold <= 0 {
+		return nil
+	}
+	var failed []string
+	for _, pkg := range collectExported(coverage, opts).Packages {
+		if rate := pkg.Rate * 100; pkg.Exported > 0 && rate < opts.ExportedThreshold {
+			failed = append(failed, fmt.Sprintf("%s (%.1f%%)", pkg.Name, rate))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("exported functions covered below the threshold of %.1f%% in %s",
+			opts.ExportedThreshold, strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIsExportedFunc(t *testing.T) {
+	t.Parallel()
+
+	const src = `package p
+
+func Exported() {}
+func unexported() {}
+func (T) Method() {}
+func (*T) method() {}
+func (t) Method() {}
+func (*List[E]) Len() int { return 0 }
+`
+	parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+
+	expected := []bool{true, false, true, false, false, true}
+	for i, decl := range parsed.Decls {
+		n := decl.(*ast.FuncDecl)
+		if got := isExportedFunc(n); got != expected[i] {
+			t.Errorf("expected isExportedFunc of %s to be %t, got %t", n.Name.Name, expected[i], got)
+		}
+	}
+
+	closure := closureDecl(nil, "Exported.func1", &ast.FuncLit{Type: &ast.FuncType{}, Body: &ast.BlockStmt{}})
+	if isExportedFunc(closure) {
+		t.Errorf("expected closure %s not to be exported", closure.Name.Name)
+	}
+}
+
+func TestConvertExported(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_set.txt", &Options{Ignore: &Ignore{}, Format: "exported"})
+
+	var report exportedReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if len(report.Packages) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(report.Packages))
+	}
+	pkg := report.Packages[0]
+	if pkg.Exported != 7 || pkg.Covered != 2 || pkg.Rate != 2.0/7 {
+		t.Errorf("expected 2 of 7 exported functions covered, got %d of %d (%f)", pkg.Covered, pkg.Exported, pkg.Rate)
+	}
+
+	var uncovered []string
+	for _, function := range pkg.Uncovered {
+		uncovered = append(uncovered, function.Function)
+	}
+	slices.Sort(uncovered)
+	expected := []string{"Func3", "Func4", "Func5", "Type1.Func2b", "Type1.Func2c"}
+	if !slices.Equal(uncovered, expected) {
+		t.Errorf("expected uncovered functions %v, got %v", expected, uncovered)
+	}
+}
+
+func TestConvertExportedThreshold(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name      string
+		threshold float64
+		fail      bool
+	}{
+		{name: "disabled", threshold: 0},
+		{name: "passed", threshold: 25},
+		{name: "failed", threshold: 30, fail: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := convertTestdataErr(t, "testdata/testdata_set.txt", &Options{
+				Ignore:            &Ignore{},
+				ExportedThreshold: tc.threshold,
+			})
+			if !tc.fail {
+				if err != nil {
+					t.Errorf("expected threshold %.1f to pass, got: %v", tc.threshold, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "github.com/fasmat/gocover-cobertura/testdata (28.6%)") {
+				t.Errorf("expected threshold %.1f to fail for the testdata package, got: %v", tc.threshold, err)
+			}
+			if out.Len() == 0 {
+				t.Errorf("expected the report to be written before the threshold check")
+			}
+		})
+	}
+}
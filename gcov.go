@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// writeGcov writes a gcov style annotated copy of every source file of the report
+// into dir. The files keep their path relative to the module root and get an
+// additional .gcov extension.
+func writeGcov(dir string, cov *Coverage) error {
+	for _, file := range cov.Files {
+		name := filepath.Join(dir, filepath.FromSlash(file.Name)+".gcov")
+		err := os.MkdirAll(filepath.Dir(name), 0o755)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("create directory for %s: %w", name, err)
+		}
+		if err := writeGcovFile(name, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeGcovFile(name string, file *File) error {
+	out, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	defer out.Close()
+
+	if err := annotateGcov(out, file); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return out.Close()
+}
+
+// annotateGcov writes the source of file to w with the hit count of every line in a
+// margin column. Lines that were never executed are marked with "#####", lines that
+// are not executable with "-".
+func annotateGcov(w io.Writer, file *File) error {
+	hits := make(map[int]int64, len(file.Lines))
+	for _, line := range file.Lines {
+		hits[line.Number] = line.Hits
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%9s:%5d:Source:%s\n", "-", 0, file.Name)
+	for i, text := range file.sourceLines() {
+		count := "-"
+		if n, ok := hits[i+1]; ok {
+			count = "#####"
+			if n > 0 {
+				count = strconv.FormatInt(n, 10)
+			}
+		}
+		fmt.Fprintf(bw, "%9s:%5d:%s\n", count, i+1, text)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnnotateGcov(t *testing.T) {
+	t.Parallel()
+
+	file := &File{
+		Name: "pkg/foo.go",
+		Lines: Lines{
+			{Number: 3, Hits: 2},
+			{Number: 4, Hits: 0},
+		},
+		data: []byte("package pkg\r\n\nfunc Foo() {\n\tbar()\n}\n"),
+	}
+
+	out := new(bytes.Buffer)
+	if err := annotateGcov(out, file); err != nil {
+		t.Fatalf("annotateGcov failed: %v", err)
+	}
+
+	expected := `        -:    0:Source:pkg/foo.go
+        -:    1:package pkg
+        -:    2:
+        2:    3:func Foo() {
+    #####:    4:	bar()
+        -:    5:}
+`
+	if out.String() != expected {
+		t.Errorf("annotateGcov result:\nGot:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestConvertGcov(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	convertTestdata(t, "testdata/testdata_set.txt", &Options{GcovDir: dir})
+
+	data, err := os.ReadFile(filepath.Join(dir, "testdata", "func1.go.gcov"))
+	if err != nil {
+		t.Fatalf("failed to read gcov file: %v", err)
+	}
+	for _, line := range []string{
+		"        -:    0:Source:testdata/func1.go",
+		"        -:    5:func Func1(arg1 *int) {",
+		"        1:    6:\tif *arg1 != 0 {",
+		"    #####:    7:\t\t*arg1 = 1",
+		"        -:    8:\t}",
+		"        -:    9:}",
+	} {
+		if !strings.Contains(string(data), line+"\n") {
+			t.Errorf("missing line %q in gcov file:\n%s", line, data)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "testdata", "func2.go.gcov")); err != nil {
+		t.Errorf("expected gcov file for func2.go: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "testdata", "func3.go.gcov")); err == nil {
+		t.Errorf("expected no gcov file for ignored func3.go")
+	}
+}
@@ -6,6 +6,7 @@
 package main
 
 import (
+	"cmp"
 	"encoding/xml"
 	"errors"
 	"flag"
@@ -14,13 +15,16 @@ import (
 	"go/parser"
 	"go/token"
 	"io"
-	"io/fs"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"golang.org/x/tools/cover"
@@ -50,21 +54,137 @@ func printHelp() {
 	flag.PrintDefaults()
 }
 
+// Options configures how a coverage profile is converted and which reports are written.
+type Options struct {
+	// Ignore selects the files that are excluded from the report.
+	Ignore *Ignore
+	// ByFiles organizes the coverage by file instead of by receiver type.
+	ByFiles bool
+	// BuildTags are the build tags used to load the packages of the profile.
+	BuildTags string
+	// StatementRates computes all line rates from the statements of the profile instead
+	// of from lines, like go tool cover does.
+	StatementRates bool
+	// ClassGrouping is how methods are grouped into classes, see classGroupings.
+	ClassGrouping string
+	// LineDirectives attributes the coverage of generated code to the sources named by its
+	// //line directives.
+	LineDirectives bool
+	// MethodNames is the style of the method names, see methodNameStyles.
+	MethodNames string
+	// TypeParams adds the type parameters of generic receiver types to the class names,
+	// e.g. "List[T]" instead of "List".
+	TypeParams bool
+	// Closures reports function literals in functions as methods of their own instead of
+	// as part of the function they are declared in.
+	Closures bool
+	// LineHits is the policy merging the counts of all blocks on a line, see lineHitPolicies.
+	LineHits string
+	// SignatureStyle is the style of the method signatures, see signatureStyles.
+	SignatureStyle string
+	// Format is the name of the default report format, see formats.
+	Format string
+	// Outputs are the reports to write. If empty a single report in Format is written.
+	Outputs []Output
+	// GcovDir is the directory gcov style annotated sources are written to. Empty disables them.
+	GcovDir string
+	// BadgeFile is the file an SVG badge with the total coverage is written to. Empty disables it.
+	BadgeFile string
+	// BadgePackagesDir is the directory an SVG badge for every package is written to. Empty disables them.
+	BadgePackagesDir string
+	// BadgeLabel is the label shown on the badges.
+	BadgeLabel string
+	// BadgeColors are the color bands of the badges, sorted by threshold.
+	BadgeColors []BadgeColor
+	// CSVPerFile writes one row for every file instead of every method to the csv report.
+	CSVPerFile bool
+	// MetricsPerFile adds a series for every file to the openmetrics report.
+	MetricsPerFile bool
+	// Template is the template executed on the coverage model for the template report.
+	Template *template.Template
+	// HotBlocks is the number of blocks executed most often listed in the calls report.
+	HotBlocks int
+	// CallAttributes adds how often every function was entered to the Cobertura report.
+	CallAttributes bool
+	// SARIFThreshold is the line coverage in percent below which a function is reported
+	// in the SARIF report. Functions without any coverage are always reported.
+	SARIFThreshold float64
+	// ExportedThreshold is the share in percent of the exported functions of every package
+	// that must have any hits. Conversion fails if a package falls below it.
+	ExportedThreshold float64
+	// CRAPThreshold is the highest CRAP score a function may have. Conversion fails if
+	// any function scores above it.
+	CRAPThreshold float64
+}
+
+// formats maps the names accepted by -format and -o to the functions writing that report.
+var formats = map[string]func(out io.Writer, coverage *Coverage, opts *Options) error{
+	"calls":       writeCalls,
+	"calls-csv":   writeCallsCSV,
+	"cobertura":   writeCobertura,
+	"codeclimate": writeCodeClimate,
+	"crap":        writeCRAP,
+	"crap-json":   writeCRAPJSON,
+	"crap-md":     writeCRAPMarkdown,
+	"csv":         writeCSV,
+	"exported":    writeExported,
+	"openmetrics": writeOpenMetrics,
+	"paths":       writePaths,
+	"pprof":       writePprof,
+	"sarif":       writeSARIF,
+	"template":    writeTemplate,
+}
+
 func main() {
 	var ignore Ignore
-	var byFiles bool
 	var help bool
+	opts := Options{Ignore: &ignore}
 	inFile := os.Stdin
-	outFile := os.Stdout
 
 	inFileName := flag.String("f", "", "path to coverage file (default: stdin)")
-	outFileName := flag.String("o", "", "path to output file (default: stdout)")
+	flag.Var((*outputsFlag)(&opts.Outputs), "o",
+		"path to output file, or FORMAT=PATH to write a report in another format; can be repeated (default: stdout)")
 	flag.BoolVar(&help, "h", false, "show help")
-	flag.BoolVar(&byFiles, "by-files", false, "code coverage by file, not class")
+	flag.BoolVar(&opts.ByFiles, "by-files", false, "code coverage by file, not class")
 	flag.BoolVar(&ignore.GeneratedFiles, "ignore-gen-files", false, "ignore generated files")
 	ignoreDirsRe := flag.String("ignore-dirs", "", "ignore dirs matching this regexp")
 	ignoreFilesRe := flag.String("ignore-files", "", "ignore files matching this regexp")
-	buildTags := flag.String("tags", "", "build tags to use when loading packages")
+	flag.StringVar(&opts.BuildTags, "tags", "", "build tags to use when loading packages")
+	rates := flag.String("rates", "lines", "compute line rates from lines or statements (like go tool cover)")
+	flag.StringVar(&opts.ClassGrouping, "classes", "receiver",
+		"group methods into classes by: "+strings.Join(slices.Sorted(maps.Keys(classGroupings)), ", "))
+	flag.StringVar(&opts.MethodNames, "method-names", "plain",
+		"style of the method names: plain, qualified or file (qualified and unique in the package)")
+	flag.BoolVar(&opts.TypeParams, "type-params", false, "add the type parameters of generic types to class names")
+	flag.BoolVar(&opts.LineDirectives, "line-directives", false,
+		"attribute the coverage of generated code to the sources named by its //line directives")
+	flag.BoolVar(&opts.Closures, "closures", false, "report closures in functions as methods of their own")
+	flag.StringVar(&opts.LineHits, "line-hits", "min",
+		"merge the counts of the blocks on a line: min, max, sum or partial")
+	flag.StringVar(&opts.SignatureStyle, "signatures", "go", "style of the method signatures: go, jvm or none")
+	flag.StringVar(&opts.Format, "format", "",
+		"format of the report: "+strings.Join(slices.Sorted(maps.Keys(formats)), ", ")+
+			" (default: template if -template is given, cobertura otherwise)")
+	flag.StringVar(&opts.GcovDir, "gcov", "", "write gcov style annotated sources to this directory")
+	flag.StringVar(&opts.BadgeFile, "badge", "", "write an SVG badge with the total coverage to this file")
+	flag.StringVar(&opts.BadgePackagesDir, "badge-packages", "",
+		"write an SVG badge for every package to this directory")
+	flag.StringVar(&opts.BadgeLabel, "badge-label", "coverage", "label of the SVG badges")
+	badgeColors := flag.String("badge-colors", defaultBadgeColors,
+		"color bands of the SVG badges as PERCENT:COLOR list")
+	flag.BoolVar(&opts.CSVPerFile, "csv-per-file", false,
+		"write one row per file instead of per function to the csv report")
+	flag.BoolVar(&opts.MetricsPerFile, "metrics-per-file", false, "add per file series to the openmetrics report")
+	templateFile := flag.String("template", "",
+		"Go text/template file executed on the coverage for the template report")
+	flag.IntVar(&opts.HotBlocks, "hot-blocks", 10, "number of blocks executed most often listed in the calls report")
+	flag.BoolVar(&opts.CallAttributes, "call-attributes", false,
+		"add how often every function was entered as calls attribute to the cobertura report")
+	flag.Float64Var(&opts.SARIFThreshold, "sarif-threshold", 0,
+		"report functions with a line coverage below this percentage in the sarif report")
+	flag.Float64Var(&opts.ExportedThreshold, "exported-threshold", 0,
+		"fail if less than this percentage of the exported functions of a package have any hits")
+	flag.Float64Var(&opts.CRAPThreshold, "crap-threshold", 0, "fail if the CRAP score of a function is above this")
 	flag.Parse()
 
 	if help {
@@ -80,18 +200,6 @@ func main() {
 		}
 		defer inFile.Close()
 	}
-	if *outFileName != "" {
-		var err error
-		err = os.MkdirAll(filepath.Dir(*outFileName), 0o755)
-		if err != nil && !errors.Is(err, fs.ErrExist) {
-			log.Fatalf("Failed to create output directory for %q: %s", *outFileName, err)
-		}
-		outFile, err = os.Create(*outFileName)
-		if err != nil {
-			log.Fatalf("Failed to create output file %q: %s", *outFileName, err)
-		}
-		defer outFile.Close()
-	}
 
 	var err error
 	if *ignoreDirsRe != "" {
@@ -108,23 +216,74 @@ func main() {
 		}
 	}
 
-	if *buildTags != "" {
-		log.Printf("Using build tags: %s", *buildTags)
+	switch *rates {
+	case "lines":
+	case "statements":
+		opts.StatementRates = true
+	default:
+		log.Fatalf("Bad -rates: must be lines or statements, got %q", *rates)
+	}
+
+	if *templateFile != "" {
+		opts.Template, err = parseTemplate(*templateFile)
+		if err != nil {
+			log.Fatalf("Bad -template: %s", err)
+		}
+		if opts.Format == "" {
+			opts.Format = "template"
+		}
+	}
+
+	opts.BadgeColors, err = parseBadgeColors(*badgeColors)
+	if err != nil {
+		log.Fatalf("Bad -badge-colors: %s", err)
+	}
+
+	if opts.BuildTags != "" {
+		log.Printf("Using build tags: %s", opts.BuildTags)
 	}
 
-	if err := convert(inFile, outFile, &ignore, byFiles, *buildTags); err != nil {
+	if err := convert(inFile, os.Stdout, &opts); err != nil {
 		log.Fatalf("code coverage conversion failed: %s", err)
 	}
 }
 
-func convert(in io.Reader, out io.Writer, ignore *Ignore, byFiles bool, buildTags string) error {
-	ignoreRd := NewIgnoreReader(ignore, in)
+func convert(in io.Reader, out io.Writer, opts *Options) error {
+	outputs := slices.Clone(opts.Outputs)
+	if len(outputs) == 0 {
+		outputs = []Output{{}}
+	}
+	for i := range outputs {
+		if outputs[i].Format == "" {
+			outputs[i].Format = cmp.Or(opts.Format, "cobertura")
+		}
+		if _, ok := formats[outputs[i].Format]; !ok {
+			return fmt.Errorf("unknown report format %q", outputs[i].Format)
+		}
+	}
+	if _, ok := classGroupings[cmp.Or(opts.ClassGrouping, "receiver")]; !ok {
+		return fmt.Errorf("unknown class grouping %q", opts.ClassGrouping)
+	}
+	if _, ok := methodNameStyles[cmp.Or(opts.MethodNames, "plain")]; !ok {
+		return fmt.Errorf("unknown method name style %q", opts.MethodNames)
+	}
+	if _, ok := lineHitPolicies[cmp.Or(opts.LineHits, "min")]; !ok {
+		return fmt.Errorf("unknown line hit policy %q", opts.LineHits)
+	}
+	if _, ok := signatureStyles[cmp.Or(opts.SignatureStyle, "go")]; !ok {
+		return fmt.Errorf("unknown signature style %q", opts.SignatureStyle)
+	}
+	if opts.HotBlocks < 0 {
+		return fmt.Errorf("negative number of hot blocks %d", opts.HotBlocks)
+	}
+
+	ignoreRd := NewIgnoreReader(opts.Ignore, in)
 	profiles, err := cover.ParseProfilesFromReader(ignoreRd)
 	if err != nil {
 		return fmt.Errorf("parse profiles: %w", err)
 	}
 
-	pkgs, err := getPackages(profiles, buildTags)
+	pkgs, err := getPackages(profiles, opts.BuildTags)
 	if err != nil {
 		return fmt.Errorf("get packages: %w", err)
 	}
@@ -141,10 +300,32 @@ func convert(in io.Reader, out io.Writer, ignore *Ignore, byFiles bool, buildTag
 		Packages:  nil,
 		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
 	}
-	if err := coverage.parseProfiles(profiles, pkgMap, ignore, byFiles); err != nil {
+	if err := coverage.parseProfiles(profiles, pkgMap, opts); err != nil {
 		return fmt.Errorf("parse coverage profiles: %w", err)
 	}
 
+	for _, output := range outputs {
+		if err := writeOutput(out, &coverage, output, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.GcovDir != "" {
+		if err := writeGcov(opts.GcovDir, &coverage); err != nil {
+			return fmt.Errorf("write gcov files: %w", err)
+		}
+	}
+
+	if err := writeBadges(&coverage, opts); err != nil {
+		return fmt.Errorf("write badges: %w", err)
+	}
+	if err := checkExportedThreshold(&coverage, opts); err != nil {
+		return err
+	}
+	return checkCRAPThreshold(&coverage, opts)
+}
+
+func writeCobertura(out io.Writer, coverage *Coverage, _ *Options) error {
 	if _, err := fmt.Fprint(out, xml.Header); err != nil {
 		return fmt.Errorf("write XML header: %w", err)
 	}
@@ -209,28 +390,35 @@ func findAbsFilePath(pkg *packages.Package, profileName string) (string, error)
 func (cov *Coverage) parseProfiles(
 	profiles []*cover.Profile,
 	pkgMap map[string]*packages.Package,
-	ignore *Ignore,
-	byFiles bool,
+	opts *Options,
 ) error {
 	cov.Packages = []*Package{}
 	for _, profile := range profiles {
 		pkgName := getPackageName(profile.FileName)
 		pkgPkg := pkgMap[pkgName]
-		if err := cov.parseProfile(profile, pkgPkg, ignore, byFiles); err != nil {
+		if err := cov.parseProfile(profile, pkgPkg, opts); err != nil {
 			return err
 		}
 	}
 	cov.LinesValid = cov.NumLines()
 	cov.LinesCovered = cov.NumLinesWithHits()
 	cov.LineRate = cov.HitRate()
+	if opts.StatementRates {
+		cov.LineRate = cov.StatementRate()
+		cov.StatementsValid = cov.NumStatements()
+		cov.StatementsCovered = cov.NumStatementsWithHits()
+	}
+	cov.BranchesValid = cov.NumBranches()
+	cov.BranchesCovered = cov.NumBranchesCovered()
+	cov.BranchRate = cov.BranchHitRate()
+	cov.Complexity = cov.AverageComplexity()
 	return nil
 }
 
 func (cov *Coverage) parseProfile(
 	profile *cover.Profile,
 	pkgPkg *packages.Package,
-	ignore *Ignore,
-	byFiles bool,
+	opts *Options,
 ) error {
 	if pkgPkg == nil || pkgPkg.Module == nil {
 		return errors.New("package required when using go modules")
@@ -250,7 +438,7 @@ func (cov *Coverage) parseProfile(
 		return fmt.Errorf("read file %s: %w", absFilePath, err)
 	}
 
-	if ignore.Match(fileName, data) {
+	if opts.Ignore.Match(fileName, data) {
 		return nil
 	}
 
@@ -267,76 +455,193 @@ func (cov *Coverage) parseProfile(
 		}
 	}
 	if pkg == nil {
-		pkg = &Package{Name: pkgPkg.ID, Classes: []*Class{}}
+		pkg = &Package{
+			Name:        pkgPkg.ID,
+			Classes:     []*Class{},
+			classes:     make(map[string]*Class),
+			methodNames: make(map[string]int),
+			funcLits:    make(map[string]int),
+		}
 		cov.Packages = append(cov.Packages, pkg)
 	}
+	file := &File{Name: fileName, Path: absFilePath, Package: pkg.Name, Lines: []*Line{}, data: data}
+	cov.Files = append(cov.Files, file)
 	visitor := &fileVisitor{
-		fset:     fset,
-		fileName: fileName,
-		fileData: data,
-		byFiles:  byFiles,
-		classes:  make(map[string]*Class),
-		pkg:      pkg,
-		profile:  profile,
+		fset:           fset,
+		fileName:       fileName,
+		byFiles:        opts.ByFiles,
+		statementRates: opts.StatementRates,
+		lineHits:       cmp.Or(opts.LineHits, "min"),
+		closures:       opts.Closures,
+		typeParams:     opts.TypeParams,
+		signature:      signatureStyles[cmp.Or(opts.SignatureStyle, "go")],
+		methodNames:    cmp.Or(opts.MethodNames, "plain"),
+		classGrouping:  classGroupings[cmp.Or(opts.ClassGrouping, "receiver")],
+		groupByType:    opts.ClassGrouping == "type",
+		classes:        make(map[string]*Class),
+		file:           file,
+		pkg:            pkg,
+		profile:        profile,
+		lineDirectives: opts.LineDirectives,
+		callAttributes: opts.CallAttributes,
+		tokenFile:      fset.File(parsed.Pos()),
+		moduleDir:      pkgPkg.Module.Dir,
+		files:          &cov.Files,
 	}
 	ast.Walk(visitor, parsed)
 	pkg.LineRate = pkg.HitRate()
+	if opts.StatementRates {
+		pkg.LineRate = pkg.StatementRate()
+	}
+	pkg.BranchRate = pkg.BranchHitRate()
+	pkg.Complexity = pkg.AverageComplexity()
 	return nil
 }
 
 type fileVisitor struct {
-	fset     *token.FileSet
-	fileName string
-	fileData []byte
-	pkg      *Package
-	byFiles  bool
-	classes  map[string]*Class
-	profile  *cover.Profile
+	fset           *token.FileSet
+	fileName       string
+	file           *File
+	pkg            *Package
+	byFiles        bool
+	statementRates bool
+	lineHits       string
+	closures       bool
+	typeParams     bool
+	signature      func(n *ast.FuncDecl) string
+	methodNames    string
+	classGrouping  func(n *ast.FuncDecl, fileName string) (name, typeParams string)
+	groupByType    bool
+	classes        map[string]*Class
+	profile        *cover.Profile
+	lineDirectives bool
+	callAttributes bool
+	tokenFile      *token.File
+	moduleDir      string
+	files          *[]*File
 }
 
 func (v *fileVisitor) Visit(node ast.Node) ast.Visitor {
 	switch n := node.(type) {
 	case *ast.FuncDecl:
-		class := v.class(n)
-		method := v.method(n)
-		method.LineRate = method.Lines.HitRate()
-		class.Methods = append(class.Methods, method)
-		class.Lines = append(class.Lines, method.Lines...)
-
-		class.LineRate = class.Lines.HitRate()
+		v.function(n, n.Name.Name+".func")
+		return nil
+	case *ast.ValueSpec:
+		// Function literals at package level are named after the variable they are assigned to.
+		for i, value := range n.Values {
+			name := "glob"
+			if len(n.Names) == len(n.Values) && n.Names[i].Name != "_" {
+				name = n.Names[i].Name
+			}
+			for _, lit := range funcLits(value) {
+				v.pkg.funcLits[name]++
+				decl := closureDecl(nil, name+".func"+strconv.Itoa(v.pkg.funcLits[name]), lit)
+				v.function(decl, decl.Name.Name+".")
+			}
+		}
+		return nil
 	}
 	return v
 }
 
-func (v *fileVisitor) method(n *ast.FuncDecl) *Method {
-	method := &Method{Name: n.Name.Name}
-	method.Lines = []*Line{}
+// function adds a method for the function n to its class. If closures are reported, they
+// are added as methods of their own, named closurePrefix followed by their index.
+func (v *fileVisitor) function(n *ast.FuncDecl, closurePrefix string) {
+	if v.lineDirectives {
+		// Report the function in the file it was generated from.
+		defer func(fileName string, file *File) { v.fileName, v.file = fileName, file }(v.fileName, v.file)
+		v.fileName, v.file = v.originalFile(n)
+	}
+
+	var closures []*ast.FuncLit
+	if v.closures {
+		closures = funcLits(n.Body)
+	}
+
+	class := v.class(n)
+	method := v.method(n, closures)
+	method.Name = v.methodName(n)
+	method.Signature = v.signature(n)
+	method.exported = isExportedFunc(n)
+	if n.Recv != nil {
+		method.recv, _ = recvType(n)
+	}
+	if v.callAttributes {
+		calls := method.NumCalls()
+		method.Calls = &calls
+	}
+	v.branches(n.Body, method)
+	v.classifyPaths(n.Body, method)
+	method.LineRate = method.HitRate()
+	if v.statementRates {
+		method.LineRate = method.StatementRate()
+	}
+	method.BranchRate = method.Lines.BranchHitRate()
+	method.Complexity = float32(cyclomaticComplexity(n.Body, closures))
+	class.Methods = append(class.Methods, method)
+	class.Lines = mergeLines(class.Lines, method.Lines, v.lineHits)
+	v.file.Lines = mergeLines(v.file.Lines, method.Lines, v.lineHits)
+	v.file.methods = append(v.file.methods, method)
+
+	class.LineRate = class.HitRate()
+	if v.statementRates {
+		class.LineRate = class.StatementRate()
+	}
+	class.BranchRate = class.BranchHitRate()
+	class.Complexity = class.AverageComplexity()
+
+	for i, lit := range closures {
+		decl := closureDecl(n.Recv, closurePrefix+strconv.Itoa(i+1), lit)
+		v.function(decl, decl.Name.Name+".")
+	}
+}
+
+func (v *fileVisitor) method(n *ast.FuncDecl, closures []*ast.FuncLit) *Method {
+	start := v.fset.PositionFor(n.Pos(), false)
+	end := v.fset.PositionFor(n.End(), false)
+	method := &Method{
+		Name:      n.Name.Name,
+		Filename:  v.fileName,
+		StartLine: v.reportedLine(start.Line),
+		EndLine:   v.reportedLine(end.Line),
+	}
 
-	start := v.fset.Position(n.Pos())
-	end := v.fset.Position(n.End())
 	startLine := start.Line
 	startCol := start.Column
 	endLine := end.Line
 	endCol := end.Column
+	executable := executableColumns(v.fset, n.Body, closures)
+	counts := make(map[int][]int64)
 	// The blocks are sorted, so we can stop counting as soon as we reach the end of the relevant block.
 	for _, b := range v.profile.Blocks {
 		if b.StartLine > endLine || (b.StartLine == endLine && b.StartCol >= endCol) {
 			// Past the end of the function.
 			break
 		}
-		if b.EndLine < startLine || (b.EndLine == startLine && b.EndCol <= startCol) {
-			// Before the beginning of the function
+		if b.StartLine < startLine || (b.StartLine == startLine && b.StartCol < startCol) {
+			// Before the beginning of the function, e.g. the block declaring a closure.
 			continue
 		}
+		if v.inClosure(b, closures) {
+			// Reported as a method of its own.
+			continue
+		}
+		method.blocks = append(method.blocks, b)
 		for i := b.StartLine; i <= b.EndLine; i++ {
-			method.Lines.AddOrUpdateLine(i, int64(b.Count))
+			// Only lines with code executed as part of the block count, not blank lines,
+			// comments and braces.
+			if executableIn(b, i, executable[i]) {
+				line := v.reportedLine(i)
+				counts[line] = append(counts[line], int64(b.Count))
+			}
 		}
 	}
+	method.Lines = mergeLineHits(counts, v.lineHits)
 	return method
 }
 
 func (v *fileVisitor) class(n *ast.FuncDecl) *Class {
-	var className string
+	var className, typeParams string
 	if v.byFiles {
 		// NOTE(boumenot): ReportGenerator creates links that collide if names are not distinct.
 		// This could be an issue in how I am generating the report, but I have not been able
@@ -347,24 +652,53 @@ func (v *fileVisitor) class(n *ast.FuncDecl) *Class {
 		className = strings.ReplaceAll(v.fileName, "/", ".")
 		className = strings.ReplaceAll(className, "\\", ".")
 	} else {
-		className = v.recvName(n)
+		className, typeParams = v.classGrouping(n, v.fileName)
 	}
-	class := v.classes[className]
+	key := className
+	if v.lineDirectives {
+		// A generated file may have functions of several original files.
+		key = v.fileName + ":" + className
+	}
+	class := v.classes[key]
 	if class == nil {
-		class = &Class{Name: className, Filename: v.fileName, Methods: []*Method{}, Lines: []*Line{}}
-		v.classes[className] = class
-		v.pkg.Classes = append(v.pkg.Classes, class)
+		name := className
+		if v.typeParams {
+			// Methods of a generic type may name its type parameters differently, the
+			// first one names them for the class.
+			name += typeParams
+		}
+		class = &Class{Name: name, Filename: v.fileName, Methods: []*Method{}, Lines: []*Line{}}
+		v.classes[key] = class
+		v.addClass(class, className)
 	}
 	return class
 }
 
-func (v *fileVisitor) recvName(n *ast.FuncDecl) string {
-	if n.Recv == nil {
-		return "-"
+// addClass adds the class of the type or functions className to the package. Cobertura
+// classes belong to a single file, so if classes are grouped by type, the classes of a type
+// with methods in several files are named like its first class and listed after it. The
+// classes of functions without receiver, "-", stay separate.
+func (v *fileVisitor) addClass(class *Class, className string) {
+	if !v.groupByType || className == "-" {
+		v.pkg.Classes = append(v.pkg.Classes, class)
+		return
+	}
+	prev := v.pkg.classes[className]
+	v.pkg.classes[className] = class
+	if prev == nil {
+		v.pkg.Classes = append(v.pkg.Classes, class)
+		return
+	}
+	class.Name = prev.Name
+	v.pkg.Classes = slices.Insert(v.pkg.Classes, slices.Index(v.pkg.Classes, prev)+1, class)
+}
+
+// methodDisplayName returns the name of method qualified with its receiver type, if any.
+// The class of the method is not used, as depending on the grouping it is named after the
+// file or the function itself. Methods with names other than plain are qualified already.
+func methodDisplayName(method *Method, opts *Options) string {
+	if method.recv == "" || cmp.Or(opts.MethodNames, "plain") != "plain" {
+		return method.Name
 	}
-	recv := n.Recv.List[0].Type
-	start := v.fset.Position(recv.Pos())
-	end := v.fset.Position(recv.End())
-	name := string(v.fileData[start.Offset:end.Offset])
-	return strings.TrimSpace(strings.TrimLeft(name, "*"))
+	return method.recv + "." + method.Name
 }
@@ -6,12 +6,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -129,7 +133,7 @@ func TestConvertParseProfilesError(t *testing.T) {
 
 	out := new(bytes.Buffer)
 
-	err := convert(strings.NewReader("invalid data"), out, &Ignore{}, false, "")
+	err := convert(strings.NewReader("invalid data"), out, &Options{Ignore: &Ignore{}})
 	if err == nil || !strings.Contains(err.Error(), "bad mode line: invalid data") {
 		t.Fatalf("expected error about bad mode line, got: %v", err)
 	}
@@ -144,7 +148,7 @@ func TestConvertOutputError(t *testing.T) {
 		t.Fatalf("failed to close pipe2rd: %v", err)
 	}
 
-	err := convert(strings.NewReader("mode: set"), pipe2wr, &Ignore{}, false, "")
+	err := convert(strings.NewReader("mode: set"), pipe2wr, &Options{Ignore: &Ignore{}})
 	if !errors.Is(err, io.ErrClosedPipe) {
 		t.Fatalf("expected error about closed pipe, got: %v", err)
 	}
@@ -156,7 +160,7 @@ func TestConvertEmpty(t *testing.T) {
 	data := `mode: set`
 	out := new(bytes.Buffer)
 
-	err := convert(strings.NewReader(data), out, &Ignore{}, false, "")
+	err := convert(strings.NewReader(data), out, &Options{Ignore: &Ignore{}})
 	if err != nil {
 		t.Fatalf("convert failed: %v", err)
 	}
@@ -184,7 +188,7 @@ func TestParseProfileNilPackages(t *testing.T) {
 
 	v := Coverage{}
 	profile := cover.Profile{FileName: "does-not-exist"}
-	err := v.parseProfile(&profile, nil, &Ignore{}, false)
+	err := v.parseProfile(&profile, nil, &Options{Ignore: &Ignore{}})
 	if err == nil || !strings.Contains(err.Error(), "package required when using go modules") {
 		t.Fatalf("expected error about missing package, got: %v", err)
 	}
@@ -195,7 +199,7 @@ func TestParseProfileEmptyPackages(t *testing.T) {
 
 	v := Coverage{}
 	profile := cover.Profile{FileName: "does-not-exist"}
-	err := v.parseProfile(&profile, &packages.Package{}, &Ignore{}, false)
+	err := v.parseProfile(&profile, &packages.Package{}, &Options{Ignore: &Ignore{}})
 	if err == nil || !strings.Contains(err.Error(), "package required when using go modules") {
 		t.Fatalf("expected error about missing package, got: %v", err)
 	}
@@ -212,7 +216,7 @@ func TestParseProfileDoesNotExist(t *testing.T) {
 		Module: &packages.Module{},
 	}
 
-	err := v.parseProfile(&profile, &pkg, &Ignore{}, false)
+	err := v.parseProfile(&profile, &pkg, &Options{Ignore: &Ignore{}})
 	if !strings.Contains(err.Error(), fmt.Sprintf("file %s not found", profile.FileName)) {
 		t.Fatalf("expected error about file not existing, got: %v", err)
 	}
@@ -223,7 +227,7 @@ func TestParseProfileNotReadable(t *testing.T) {
 
 	v := Coverage{}
 	profile := cover.Profile{FileName: os.DevNull}
-	err := v.parseProfile(&profile, nil, &Ignore{}, false)
+	err := v.parseProfile(&profile, nil, &Options{Ignore: &Ignore{}})
 	if err == nil || !strings.Contains(err.Error(), "package required when using go modules") {
 		t.Fatalf("expected error about missing package, got: %v", err)
 	}
@@ -255,7 +259,7 @@ func TestParseProfilePermissionDenied(t *testing.T) {
 			Path: filepath.Dir(tempFile.Name()),
 		},
 	}
-	err = v.parseProfile(&profile, &pkg, &Ignore{}, false)
+	err = v.parseProfile(&profile, &pkg, &Options{Ignore: &Ignore{}})
 	if !errors.Is(err, fs.ErrPermission) {
 		t.Fatalf("expected permission denied error, got: %v", err)
 	}
@@ -278,28 +282,13 @@ func TestConvert(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			src, err := os.Open("testdata/testdata_set.txt")
-			if err != nil {
-				t.Fatalf("failed to open testdata_set.txt: %v", err)
-			}
-			defer src.Close()
-
-			out := new(bytes.Buffer)
-
-			err = convert(src, out, &Ignore{
-				GeneratedFiles: true,
-				Files:          regexp.MustCompile(`[\\/]func[45]\.go$`),
-			}, tc.byFiles, "testdata")
-			if err != nil {
-				t.Fatalf("convert failed: %v", err)
-			}
-
-			v := Coverage{}
-			dec := xml.NewDecoder(out)
-			err = dec.Decode(&v)
-			if err != nil {
-				t.Fatalf("failed to decode XML: %v", err)
-			}
+			v := decodeCoverage(t, convertTestdata(t, "testdata/testdata_set.txt", &Options{
+				Ignore: &Ignore{
+					GeneratedFiles: true,
+					Files:          regexp.MustCompile(`[\\/]func[45]\.go$`),
+				},
+				ByFiles: tc.byFiles,
+			}))
 
 			assertCoverage(t, v)
 			p := v.Packages[0]
@@ -323,27 +312,64 @@ func TestConvert(t *testing.T) {
 	}
 }
 
+// convertTestdata converts the coverage profile at path with opts and returns the report
+// written to the output. Unless opts.Ignore is set, func3.go, func4.go and func5.go are
+// ignored.
+func convertTestdata(t *testing.T, path string, opts *Options) *bytes.Buffer {
+	t.Helper()
+
+	out, err := convertTestdataErr(t, path, opts)
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	return out
+}
+
+// convertTestdataErr is like convertTestdata, but returns the error of the conversion
+// instead of failing the test, e.g. for failed thresholds.
+func convertTestdataErr(t *testing.T, path string, opts *Options) (*bytes.Buffer, error) {
+	t.Helper()
+
+	src, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer src.Close()
+
+	if opts.Ignore == nil {
+		opts.Ignore = &Ignore{Files: regexp.MustCompile(`[\\/]func[345]\.go$`)}
+	}
+	opts.BuildTags = "testdata"
+	out := new(bytes.Buffer)
+	return out, convert(src, out, opts)
+}
+
+// decodeCoverage decodes the Cobertura report in r.
+func decodeCoverage(t *testing.T, r io.Reader) Coverage {
+	t.Helper()
+
+	v := Coverage{}
+	if err := xml.NewDecoder(r).Decode(&v); err != nil {
+		t.Fatalf("failed to decode XML: %v", err)
+	}
+	return v
+}
+
 func assertMethod(t *testing.T, m *Method) {
 	t.Helper()
 
 	if m.Name != "Func1" {
 		t.Errorf("expected method name 'Func1', got '%s'", m.Name)
 	}
-	if len(m.Lines) != 4 {
-		t.Fatalf("expected 4 lines in method, got %d", len(m.Lines))
+	if len(m.Lines) != 2 {
+		t.Fatalf("expected 2 lines in method, got %d", len(m.Lines))
 	}
 
-	if m.Lines[0].Number != 5 || m.Lines[0].Hits != 1 {
-		t.Errorf("expected line 5 with 1 hit, got %d hits", m.Lines[0].Hits)
+	if m.Lines[0].Number != 6 || m.Lines[0].Hits != 1 {
+		t.Errorf("expected line 6 with 1 hit, got %d hits", m.Lines[0].Hits)
 	}
-	if m.Lines[1].Number != 6 || m.Lines[1].Hits != 0 {
-		t.Errorf("expected line 6 with 0 hits, got %d hits", m.Lines[1].Hits)
-	}
-	if m.Lines[2].Number != 7 || m.Lines[2].Hits != 0 {
-		t.Errorf("expected line 7 with 0 hits, got %d hits", m.Lines[2].Hits)
-	}
-	if m.Lines[3].Number != 8 || m.Lines[3].Hits != 0 {
-		t.Errorf("expected line 8 with 0 hits, got %d hits", m.Lines[3].Hits)
+	if m.Lines[1].Number != 7 || m.Lines[1].Hits != 0 {
+		t.Errorf("expected line 7 with 0 hits, got %d hits", m.Lines[1].Hits)
 	}
 }
 
@@ -359,20 +385,14 @@ func assertClass(t *testing.T, c *Class, className string) {
 	if len(c.Methods) != 1 {
 		t.Fatalf("expected 1 method, got %d", len(c.Methods))
 	}
-	if len(c.Lines) != 4 {
-		t.Fatalf("expected 4 lines, got %d", len(c.Lines))
-	}
-	if c.Lines[0].Number != 5 || c.Lines[0].Hits != 1 {
-		t.Errorf("expected line 5 with 1 hit, got %d hits", c.Lines[0].Hits)
-	}
-	if c.Lines[1].Number != 6 || c.Lines[1].Hits != 0 {
-		t.Errorf("expected line 6 with 0 hits, got %d hits", c.Lines[1].Hits)
+	if len(c.Lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(c.Lines))
 	}
-	if c.Lines[2].Number != 7 || c.Lines[2].Hits != 0 {
-		t.Errorf("expected line 7 with 0 hits, got %d hits", c.Lines[2].Hits)
+	if c.Lines[0].Number != 6 || c.Lines[0].Hits != 1 {
+		t.Errorf("expected line 6 with 1 hit, got %d hits", c.Lines[0].Hits)
 	}
-	if c.Lines[3].Number != 8 || c.Lines[3].Hits != 0 {
-		t.Errorf("expected line 8 with 0 hits, got %d hits", c.Lines[3].Hits)
+	if c.Lines[1].Number != 7 || c.Lines[1].Hits != 0 {
+		t.Errorf("expected line 7 with 0 hits, got %d hits", c.Lines[1].Hits)
 	}
 }
 
@@ -402,3 +422,216 @@ func assertCoverage(t *testing.T, v Coverage) {
 		t.Fatalf("expected 1 package, got %d", len(v.Packages))
 	}
 }
+
+func TestConvertUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	err := convert(strings.NewReader("mode: set"), new(bytes.Buffer), &Options{Ignore: &Ignore{}, Format: "foo"})
+	if err == nil || !strings.Contains(err.Error(), `unknown report format "foo"`) {
+		t.Fatalf("expected error about unknown format, got: %v", err)
+	}
+}
+
+func TestConvertStatementRates(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name           string
+		statementRates bool
+		lineRate       float32
+		statements     int64
+		covered        int64
+	}{
+		{name: "lines", lineRate: 0.5},
+		{name: "statements", statementRates: true, lineRate: 0.6, statements: 5, covered: 3},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := decodeCoverage(t, convertTestdata(t, "testdata/testdata_statements.txt", &Options{
+				Ignore:         &Ignore{},
+				StatementRates: tc.statementRates,
+			}))
+
+			// Line counts stay line counts, only the rates switch to statements.
+			if v.LineRate != tc.lineRate || v.LinesValid != 4 || v.LinesCovered != 2 {
+				t.Errorf("expected line rate %f with 4 valid and 2 covered lines, got %f with %d and %d",
+					tc.lineRate, v.LineRate, v.LinesValid, v.LinesCovered)
+			}
+			if v.StatementsValid != tc.statements || v.StatementsCovered != tc.covered {
+				t.Errorf("expected %d valid and %d covered statements, got %d and %d",
+					tc.statements, tc.covered, v.StatementsValid, v.StatementsCovered)
+			}
+			if m := v.Packages[0].Classes[0].Methods[0]; m.LineRate != tc.lineRate {
+				t.Errorf("expected line rate %f for %s, got %f", tc.lineRate, m.Name, m.LineRate)
+			}
+		})
+	}
+}
+
+func TestConvertEmptyFunctions(t *testing.T) {
+	t.Parallel()
+
+	// Only the empty functions Func2b and Func2c of func2.go, of which Func2b was called.
+	const profile = `mode: set
+github.com/fasmat/gocover-cobertura/testdata/func2.go:14.36,15.2 0 1
+github.com/fasmat/gocover-cobertura/testdata/func2.go:17.36,18.2 0 0
+`
+	out := new(bytes.Buffer)
+	err := convert(strings.NewReader(profile), out, &Options{Ignore: &Ignore{}, BuildTags: "testdata"})
+	if err != nil {
+		t.Fatalf("convert failed: %v", err)
+	}
+	v := decodeCoverage(t, out)
+
+	if v.LineRate != 0 || v.Packages[0].LineRate != 0 || v.Packages[0].Classes[0].LineRate != 0 {
+		t.Errorf("expected line rate 0 without lines, got %f, %f and %f",
+			v.LineRate, v.Packages[0].LineRate, v.Packages[0].Classes[0].LineRate)
+	}
+	expected := map[string]float32{"Func2a": 0, "Func2b": 1, "Func2c": 0}
+	for _, m := range v.Packages[0].Classes[0].Methods {
+		if m.LineRate != expected[m.Name] {
+			t.Errorf("expected line rate %f for %s, got %f", expected[m.Name], m.Name, m.LineRate)
+		}
+	}
+}
+
+func TestRecvType(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		decl       string
+		name       string
+		typeParams string
+	}{
+		{`func Func1() {}`, "-", ""},
+		{`func (r Type1) Func2a() {}`, "Type1", ""},
+		{`func (r *Type1) Func2b() {}`, "Type1", ""},
+		{`func (l *List[T]) Push(v T) {}`, "List", "[T]"},
+		{`func (l List[E]) Len() int {}`, "List", "[E]"},
+		{`func (p *(Pair[K, V])) Swap() {}`, "Pair", "[K, V]"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.decl, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\n"+tc.decl, 0)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+			name, typeParams := recvType(parsed.Decls[0].(*ast.FuncDecl))
+			if name != tc.name || typeParams != tc.typeParams {
+				t.Errorf("expected %q and %q, got %q and %q", tc.name, tc.typeParams, name, typeParams)
+			}
+		})
+	}
+}
+
+func TestConvertGenerics(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name       string
+		typeParams bool
+		expected   []string
+	}{
+		{"default", false, []string{"List", "Pair"}},
+		{"typeParams", true, []string{"List[T]", "Pair[K, V]"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			v := decodeCoverage(t, convertTestdata(t, "testdata/testdata_generic.txt", &Options{
+				Ignore:     &Ignore{},
+				TypeParams: tc.typeParams,
+			}))
+
+			classes := v.Packages[0].Classes
+			if len(classes) != len(tc.expected) {
+				t.Fatalf("expected %d classes, got %d", len(tc.expected), len(classes))
+			}
+			for i, class := range classes {
+				if class.Name != tc.expected[i] {
+					t.Errorf("expected class %s, got %s", tc.expected[i], class.Name)
+				}
+			}
+			if len(classes[0].Methods) != 2 {
+				t.Errorf("expected Push and Len in one class, got %d methods", len(classes[0].Methods))
+			}
+		})
+	}
+}
+
+func TestConvertClassGrouping(t *testing.T) {
+	t.Parallel()
+
+	type class struct {
+		name  string
+		file  string
+		lines []int
+	}
+	var (
+		func1   = class{"-", "testdata/func1.go", []int{6, 7}}
+		type1   = class{"Type1", "testdata/func2.go", []int{9, 10}}
+		func2e  = class{"-", "testdata/func2_ext.go", []int{6}}
+		type1ex = class{"Type1", "testdata/func2_ext.go", []int{10}}
+	)
+	tt := []struct {
+		grouping string
+		expected []class
+	}{
+		{"receiver", []class{func1, type1, func2e, type1ex}},
+		{"type", []class{func1, type1, type1ex, func2e}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.grouping, func(t *testing.T) {
+			t.Parallel()
+
+			v := decodeCoverage(t, convertTestdata(t, "testdata/testdata_split.txt", &Options{
+				Ignore:        &Ignore{},
+				ClassGrouping: tc.grouping,
+			}))
+
+			// Every class only holds the lines of its own file.
+			var classes []class
+			for _, c := range v.Packages[0].Classes {
+				var numbers []int
+				for _, line := range c.Lines {
+					numbers = append(numbers, line.Number)
+				}
+				for _, m := range c.Methods {
+					for _, line := range m.Lines {
+						if !slices.Contains(numbers, line.Number) {
+							t.Errorf("expected line %d of method %s in class %s of %s", line.Number, m.Name, c.Name,
+								c.Filename)
+						}
+					}
+				}
+				classes = append(classes, class{c.Name, c.Filename, numbers})
+			}
+			if !slices.EqualFunc(classes, tc.expected, func(a, b class) bool {
+				return a.name == b.name && a.file == b.file && slices.Equal(a.lines, b.lines)
+			}) {
+				t.Errorf("expected classes %v, got %v", tc.expected, classes)
+			}
+		})
+	}
+}
+
+func TestConvertUnknownClassGrouping(t *testing.T) {
+	t.Parallel()
+
+	err := convert(strings.NewReader("mode: set"), new(bytes.Buffer), &Options{
+		Ignore:        &Ignore{},
+		ClassGrouping: "foo",
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown class grouping "foo"`) {
+		t.Fatalf("expected error about unknown class grouping, got: %v", err)
+	}
+}
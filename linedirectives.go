@@ -0,0 +1,43 @@
+package main
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+)
+
+// reportedLine returns the line a line of the profile is reported at. The profile ignores
+// //line directives like go tool cover does, but if they are followed the line is mapped
+// to the line of the original source they name.
+func (v *fileVisitor) reportedLine(line int) int {
+	if !v.lineDirectives || line < 1 || line > v.tokenFile.LineCount() {
+		return line
+	}
+	return v.tokenFile.PositionFor(v.tokenFile.LineStart(line), true).Line
+}
+
+// originalFile returns the file the //line directives in effect at the declaration of n
+// attribute it to, named relative to the module like the files of the profile. Files are
+// added to the coverage the first time they are seen.
+func (v *fileVisitor) originalFile(n *ast.FuncDecl) (fileName string, file *File) {
+	path := v.tokenFile.PositionFor(n.Pos(), true).Filename
+	if path == v.file.Path {
+		return v.fileName, v.file
+	}
+
+	fileName = filepath.ToSlash(path)
+	if rel, err := filepath.Rel(v.moduleDir, path); err == nil && filepath.IsLocal(rel) {
+		fileName = filepath.ToSlash(rel)
+	}
+	for _, file := range *v.files {
+		if file.Path == path {
+			return fileName, file
+		}
+	}
+	// The source is only needed for annotated reports like gcov, which are left empty for
+	// sources that do not exist anymore.
+	data, _ := os.ReadFile(path)
+	file = &File{Name: fileName, Path: path, Package: v.pkg.Name, Lines: []*Line{}, data: data}
+	*v.files = append(*v.files, file)
+	return fileName, file
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConvertLineDirectives(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name       string
+		directives bool
+		filename   string
+		lines      []int
+		hits       []int64
+	}{
+		{"generated", false, "testdata/grammar.go", []int{7, 8, 10}, []int64{1, 0, 1}},
+		{"original", true, "testdata/grammar.y", []int{4, 5, 7}, []int64{1, 0, 1}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			out := convertTestdata(t, "testdata/testdata_directives.txt", &Options{
+				Ignore:         &Ignore{},
+				LineDirectives: tc.directives,
+				GcovDir:        dir,
+			})
+
+			v := decodeCoverage(t, out)
+
+			class := v.Packages[0].Classes[0]
+			if class.Filename != tc.filename {
+				t.Errorf("expected class filename %s, got %s", tc.filename, class.Filename)
+			}
+			var lines []int
+			var hits []int64
+			for _, line := range class.Methods[0].Lines {
+				lines = append(lines, line.Number)
+				hits = append(hits, line.Hits)
+			}
+			if !slices.Equal(lines, tc.lines) || !slices.Equal(hits, tc.hits) {
+				t.Errorf("expected lines %v with hits %v, got %v with %v", tc.lines, tc.hits, lines, hits)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, tc.filename+".gcov"))
+			if err != nil {
+				t.Fatalf("failed to read gcov file: %v", err)
+			}
+			if !strings.Contains(string(data), fmt.Sprintf("    #####:%5d:", tc.lines[1])) {
+				t.Errorf("expected line %d not to be executed:\n%s", tc.lines[1], data)
+			}
+		})
+	}
+}
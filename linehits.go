@@ -0,0 +1,83 @@
+package main
+
+import (
+	"maps"
+	"slices"
+)
+
+// lineHitPolicies maps the names accepted by -line-hits to the functions merging the
+// counts of all blocks on a line into the hits of that line.
+var lineHitPolicies = map[string]func(counts []int64) int64{
+	"min": slices.Min[[]int64],
+	"max": slices.Max[[]int64],
+	"sum": func(counts []int64) int64 {
+		var sum int64
+		for _, count := range counts {
+			sum += count
+		}
+		return sum
+	},
+	// partial counts a line as covered if any of its blocks was executed, and marks it as
+	// partially covered if some of them were not.
+	"partial": slices.Max[[]int64],
+}
+
+// mergeLineHits returns one line for every line in counts, sorted by number, with the
+// counts of the blocks on the line merged by policy. The order of the counts has no
+// influence on the result.
+func mergeLineHits(counts map[int][]int64, policy string) Lines {
+	merge := lineHitPolicies[policy]
+	lines := make(Lines, 0, len(counts))
+	for _, number := range slices.Sorted(maps.Keys(counts)) {
+		line := &Line{Number: number, Hits: merge(counts[number]), counts: counts[number]}
+		if policy == "partial" {
+			var executed int64
+			for _, count := range counts[number] {
+				if count > 0 {
+					executed++
+				}
+			}
+			if executed > 0 && executed < int64(len(counts[number])) {
+				line.markPartial(int64(len(counts[number])), executed)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// mergeLines returns the lines of lines and add with one line for every line number, sorted
+// by number. Lines in both, like a line declaring a closure that is reported as a method of
+// its own, merge the counts of all their blocks by policy and add up their branches, so a
+// class or file lists every line only once.
+func mergeLines(lines, add Lines, policy string) Lines {
+	if len(lines) == 0 || len(add) == 0 || add[0].Number > lines[len(lines)-1].Number {
+		// Methods usually follow each other, so there is nothing to merge.
+		return append(lines, add...)
+	}
+
+	byNumber := make(map[int]Lines, len(lines)+len(add))
+	for _, line := range slices.Concat(lines, add) {
+		byNumber[line.Number] = append(byNumber[line.Number], line)
+	}
+	merged := make(Lines, 0, len(byNumber))
+	for _, number := range slices.Sorted(maps.Keys(byNumber)) {
+		same := byNumber[number]
+		if len(same) == 1 {
+			merged = append(merged, same[0])
+			continue
+		}
+		var counts []int64
+		for _, line := range same {
+			counts = append(counts, line.counts...)
+		}
+		line := mergeLineHits(map[int][]int64{number: counts}, policy)[0]
+		for _, l := range same {
+			if l.branches > 0 {
+				line.addBranches(l.branches, l.branchesCovered)
+			}
+		}
+		merged = append(merged, line)
+	}
+	return merged
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMergeLineHits(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		policy  string
+		hits    []int64
+		partial string
+	}{
+		{policy: "min", hits: []int64{1, 0, 1}},
+		{policy: "max", hits: []int64{1, 2, 3}},
+		{policy: "sum", hits: []int64{1, 2, 6}},
+		{policy: "partial", hits: []int64{1, 2, 3}, partial: "50% (1/2)"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.policy, func(t *testing.T) {
+			t.Parallel()
+
+			for _, counts := range []map[int][]int64{
+				{1: {1}, 2: {2, 0}, 3: {3, 1, 2}},
+				{3: {2, 1, 3}, 2: {0, 2}, 1: {1}},
+			} {
+				lines := mergeLineHits(counts, tc.policy)
+				if len(lines) != len(tc.hits) {
+					t.Fatalf("expected %d lines, got %d", len(tc.hits), len(lines))
+				}
+				for i, line := range lines {
+					if line.Number != i+1 || line.Hits != tc.hits[i] {
+						t.Errorf("expected line %d with %d hits, got line %d with %d hits",
+							i+1, tc.hits[i], line.Number, line.Hits)
+					}
+				}
+				if lines[1].ConditionCoverage != tc.partial {
+					t.Errorf("expected condition coverage %q, got %q", tc.partial, lines[1].ConditionCoverage)
+				}
+				if lines[0].Branch || lines[2].Branch {
+					t.Errorf("expected only line 2 to be marked as partial")
+				}
+				if lines.NumBranches() != 0 {
+					t.Errorf("expected partial lines not to count as branches, got %d", lines.NumBranches())
+				}
+			}
+		})
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		policy  string
+		hits    []int64
+		partial string
+	}{
+		{policy: "min", hits: []int64{1, 2, 0}},
+		{policy: "sum", hits: []int64{1, 2, 3}},
+		{policy: "partial", hits: []int64{1, 2, 2}, partial: "66% (2/3)"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.policy, func(t *testing.T) {
+			t.Parallel()
+
+			// Line 3 has blocks of both methods, and the order of the methods does not matter.
+			outer := func() Lines { return mergeLineHits(map[int][]int64{1: {1}, 3: {1, 0}}, tc.policy) }
+			inner := func() Lines { return mergeLineHits(map[int][]int64{2: {2}, 3: {2}}, tc.policy) }
+			for _, lines := range []Lines{
+				mergeLines(outer(), inner(), tc.policy),
+				mergeLines(inner(), outer(), tc.policy),
+			} {
+				if len(lines) != len(tc.hits) {
+					t.Fatalf("expected %d lines, got %d", len(tc.hits), len(lines))
+				}
+				for i, line := range lines {
+					if line.Number != i+1 || line.Hits != tc.hits[i] {
+						t.Errorf("expected line %d with %d hits, got line %d with %d hits",
+							i+1, tc.hits[i], line.Number, line.Hits)
+					}
+				}
+				if lines[2].ConditionCoverage != tc.partial {
+					t.Errorf("expected condition coverage %q, got %q", tc.partial, lines[2].ConditionCoverage)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertUnknownLineHitPolicy(t *testing.T) {
+	t.Parallel()
+
+	err := convert(strings.NewReader("mode: set"), new(bytes.Buffer), &Options{
+		Ignore:   &Ignore{},
+		LineHits: "foo",
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown line hit policy "foo"`) {
+		t.Fatalf("expected error about unknown line hit policy, got: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"go/ast"
+	"path/filepath"
+	"strconv"
+)
+
+// methodNameStyles maps the names accepted by -method-names to the functions naming the
+// method of a function in a file. Except for plain names, repeated names are numbered to
+// be unique within the package, see (*fileVisitor).methodName.
+var methodNameStyles = map[string]func(n *ast.FuncDecl, fileName string) string{
+	// The name of the function, e.g. "Start".
+	"plain": func(n *ast.FuncDecl, _ string) string {
+		return n.Name.Name
+	},
+	// The name of the function qualified with its receiver like in stack traces, e.g.
+	// "(*Server).Start".
+	"qualified": func(n *ast.FuncDecl, _ string) string {
+		return qualifiedName(n)
+	},
+	// The qualified name prefixed with the name of the file, e.g. "server.go:init".
+	"file": func(n *ast.FuncDecl, fileName string) string {
+		return filepath.Base(fileName) + ":" + qualifiedName(n)
+	},
+}
+
+// qualifiedName returns the name of n qualified with its receiver type, e.g. "Server.Stop"
+// or "(*Server).Start".
+func qualifiedName(n *ast.FuncDecl) string {
+	if n.Recv == nil || len(n.Recv.List) == 0 {
+		return n.Name.Name
+	}
+	name, _ := recvType(n)
+	if _, ok := n.Recv.List[0].Type.(*ast.StarExpr); ok {
+		return "(*" + name + ")." + n.Name.Name
+	}
+	return name + "." + n.Name.Name
+}
+
+// methodName returns the name of the method for n. Unless names are plain, a name that
+// was already used in the package is numbered, e.g. "init#2".
+func (v *fileVisitor) methodName(n *ast.FuncDecl) string {
+	name := methodNameStyles[v.methodNames](n, v.fileName)
+	if v.methodNames == "plain" {
+		return name
+	}
+	v.pkg.methodNames[name]++
+	if count := v.pkg.methodNames[name]; count > 1 {
+		return name + "#" + strconv.Itoa(count)
+	}
+	return name
+}
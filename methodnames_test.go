@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConvertMethodNames(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		style    string
+		expected []string
+	}{
+		{"plain", []string{"Func2a", "Func2b", "Func2c", "init", "init"}},
+		{"qualified", []string{"Type1.Func2a", "(*Type1).Func2b", "(*Type1).Func2c", "init", "init#2"}},
+		{"file", []string{
+			"func2.go:Type1.Func2a", "func2.go:(*Type1).Func2b", "func2.go:(*Type1).Func2c",
+			"inits.go:init", "inits.go:init#2",
+		}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.style, func(t *testing.T) {
+			t.Parallel()
+
+			v := decodeCoverage(t, convertTestdata(t, "testdata/testdata_names.txt", &Options{
+				Ignore:      &Ignore{},
+				MethodNames: tc.style,
+			}))
+
+			var names []string
+			for _, class := range v.Packages[0].Classes {
+				for _, m := range class.Methods {
+					names = append(names, m.Name)
+				}
+			}
+			if !slices.Equal(names, tc.expected) {
+				t.Errorf("expected methods %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestConvertUnknownMethodNameStyle(t *testing.T) {
+	t.Parallel()
+
+	err := convert(strings.NewReader("mode: set"), new(bytes.Buffer), &Options{
+		Ignore:      &Ignore{},
+		MethodNames: "foo",
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown method name style "foo"`) {
+		t.Fatalf("expected error about unknown method name style, got: %v", err)
+	}
+}
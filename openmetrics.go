@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// metricsLabelEscaper escapes label values as required by the OpenMetrics text format.
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsSeries is a single sample of every coverage metric with its labels.
+type metricsSeries struct {
+	labels            string
+	valid             int64
+	covered           int64
+	statements        int64
+	statementsCovered int64
+}
+
+// writeOpenMetrics writes the line and statement coverage of the report as gauges in the OpenMetrics
+// text format, as consumed by e.g. the textfile collector of the Prometheus node exporter.
+// There is one series for the total and one for every package, and if opts.MetricsPerFile
+// is set for every file as well.
+func writeOpenMetrics(out io.Writer, coverage *Coverage, opts *Options) error {
+	series := []metricsSeries{{
+		valid:             coverage.NumLines(),
+		covered:           coverage.NumLinesWithHits(),
+		statements:        coverage.NumStatements(),
+		statementsCovered: coverage.NumStatementsWithHits(),
+	}}
+	for _, pkg := range coverage.Packages {
+		series = append(series, metricsSeries{
+			labels:            metricsLabels("package", pkg.Name),
+			valid:             pkg.NumLines(),
+			covered:           pkg.NumLinesWithHits(),
+			statements:        pkg.NumStatements(),
+			statementsCovered: pkg.NumStatementsWithHits(),
+		})
+	}
+	if opts.MetricsPerFile {
+		for _, file := range coverage.Files {
+			series = append(series, metricsSeries{
+				labels:            metricsLabels("package", file.Package, "file", file.Name),
+				valid:             file.Lines.NumLines(),
+				covered:           file.Lines.NumLinesWithHits(),
+				statements:        file.NumStatements(),
+				statementsCovered: file.NumStatementsWithHits(),
+			})
+		}
+	}
+
+	bw := bufio.NewWriter(out)
+	writeMetric(bw, "go_coverage_lines_valid", "Number of lines that can be covered by tests.",
+		series, func(s metricsSeries) string {
+			return strconv.FormatInt(s.valid, 10)
+		})
+	writeMetric(bw, "go_coverage_lines_covered", "Number of lines covered by tests.",
+		series, func(s metricsSeries) string {
+			return strconv.FormatInt(s.covered, 10)
+		})
+	writeMetric(bw, "go_coverage_line_rate", "Fraction of lines covered by tests.",
+		series, func(s metricsSeries) string {
+			return strconv.FormatFloat(coverageRate(s.covered, s.valid), 'g', -1, 64)
+		})
+	writeMetric(bw, "go_coverage_statements_valid", "Number of statements that can be covered by tests.",
+		series, func(s metricsSeries) string {
+			return strconv.FormatInt(s.statements, 10)
+		})
+	writeMetric(bw, "go_coverage_statements_covered", "Number of statements covered by tests.",
+		series, func(s metricsSeries) string {
+			return strconv.FormatInt(s.statementsCovered, 10)
+		})
+	writeMetric(bw, "go_coverage_statement_rate", "Fraction of statements covered by tests.",
+		series, func(s metricsSeries) string {
+			return strconv.FormatFloat(coverageRate(s.statementsCovered, s.statements), 'g', -1, 64)
+		})
+	fmt.Fprintln(bw, "# EOF")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write metrics: %w", err)
+	}
+	return nil
+}
+
+func writeMetric(w io.Writer, name, help string, series []metricsSeries, value func(metricsSeries) string) {
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	for _, s := range series {
+		fmt.Fprintf(w, "%s%s %s\n", name, s.labels, value(s))
+	}
+}
+
+// metricsLabels formats the given name value pairs as label set.
+func metricsLabels(nameValues ...string) string {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i < len(nameValues); i += 2 {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `%s="%s"`, nameValues[i], metricsLabelEscaper.Replace(nameValues[i+1]))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetricsLabels(t *testing.T) {
+	t.Parallel()
+
+	labels := metricsLabels("package", "foo", "file", "a\"b\\c\nd.go")
+	expected := `{package="foo",file="a\"b\\c\nd.go"}`
+	if labels != expected {
+		t.Errorf("expected labels %s, got %s", expected, labels)
+	}
+}
+
+func TestConvertOpenMetrics(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_set.txt", &Options{Format: "openmetrics", MetricsPerFile: true})
+
+	const pkg = `package="github.com/fasmat/gocover-cobertura/testdata"`
+	metrics := out.String()
+	for _, line := range []string{
+		"# TYPE go_coverage_lines_valid gauge",
+		"go_coverage_lines_valid 4",
+		"go_coverage_lines_valid{" + pkg + "} 4",
+		"go_coverage_lines_valid{" + pkg + `,file="testdata/func1.go"} 2`,
+		"go_coverage_lines_covered 3",
+		"go_coverage_lines_covered{" + pkg + `,file="testdata/func2.go"} 2`,
+		"go_coverage_line_rate{" + pkg + `,file="testdata/func1.go"} 0.5`,
+		"go_coverage_line_rate{" + pkg + `,file="testdata/func2.go"} 1`,
+		"go_coverage_statements_valid 4",
+		"go_coverage_statements_covered{" + pkg + "} 3",
+		"go_coverage_statement_rate{" + pkg + `,file="testdata/func1.go"} 0.5`,
+	} {
+		if !strings.Contains(metrics, line+"\n") {
+			t.Errorf("missing line %q in metrics:\n%s", line, metrics)
+		}
+	}
+	if !strings.HasSuffix(metrics, "# EOF\n") {
+		t.Errorf("expected metrics to end with # EOF:\n%s", metrics)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output is a report to write.
+type Output struct {
+	// Format is the name of the report format, see formats. If empty Options.Format is used.
+	Format string
+	// Path is the file the report is written to. If empty or "-" the report is written to
+	// the output passed to convert.
+	Path string
+}
+
+// outputsFlag collects the reports given with -o. Every value is either a path, in which
+// case the report has the format given with -format, or FORMAT=PATH. A value containing
+// "=" is only taken as a path if the part before it contains a path separator, so that a
+// mistyped format is not silently written to a file named after it.
+type outputsFlag []Output
+
+func (o *outputsFlag) String() string {
+	if o == nil {
+		return ""
+	}
+	values := make([]string, 0, len(*o))
+	for _, output := range *o {
+		if output.Format == "" {
+			values = append(values, output.Path)
+			continue
+		}
+		values = append(values, output.Format+"="+output.Path)
+	}
+	return strings.Join(values, ",")
+}
+
+func (o *outputsFlag) Set(value string) error {
+	format, path, ok := strings.Cut(value, "=")
+	if _, known := formats[format]; ok && !known {
+		if !strings.ContainsAny(format, "/"+string(filepath.Separator)) {
+			return fmt.Errorf("unknown format %q of output %q", format, value)
+		}
+		// The "=" is part of a path in a directory, so the whole value is the path.
+		ok = false
+	}
+	if !ok {
+		format, path = "", value
+	}
+	if path == "" {
+		return errors.New("missing path of output")
+	}
+	*o = append(*o, Output{Format: format, Path: path})
+	return nil
+}
+
+// writeOutput writes the report described by output either to its file or to out.
+func writeOutput(out io.Writer, coverage *Coverage, output Output, opts *Options) error {
+	write := formats[output.Format]
+	if output.Path == "" || output.Path == "-" {
+		return write(out, coverage, opts)
+	}
+
+	err := os.MkdirAll(filepath.Dir(output.Path), 0o755)
+	if err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("create output directory for %q: %w", output.Path, err)
+	}
+	f, err := os.Create(output.Path)
+	if err != nil {
+		return fmt.Errorf("create output file %q: %w", output.Path, err)
+	}
+	defer f.Close()
+
+	if err := write(f, coverage, opts); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputsFlag(t *testing.T) {
+	t.Parallel()
+
+	var outputs outputsFlag
+	for _, value := range []string{"coverage.xml", "csv=out/coverage.csv", "out/a=b.txt", "sarif=-"} {
+		if err := outputs.Set(value); err != nil {
+			t.Fatalf("Set(%q) failed: %v", value, err)
+		}
+	}
+
+	expected := []Output{
+		{Path: "coverage.xml"},
+		{Format: "csv", Path: "out/coverage.csv"},
+		{Path: "out/a=b.txt"},
+		{Format: "sarif", Path: "-"},
+	}
+	if len(outputs) != len(expected) {
+		t.Fatalf("expected %d outputs, got %d", len(expected), len(outputs))
+	}
+	for i := range expected {
+		if outputs[i] != expected[i] {
+			t.Errorf("expected output %+v, got %+v", expected[i], outputs[i])
+		}
+	}
+	if s := outputs.String(); s != "coverage.xml,csv=out/coverage.csv,out/a=b.txt,sarif=-" {
+		t.Errorf("unexpected string representation %q", s)
+	}
+
+	if err := outputs.Set("csv="); err == nil {
+		t.Errorf("expected error for missing path")
+	}
+	for _, value := range []string{"cvs=coverage.csv", "gcov=x", "=coverage.xml"} {
+		if err := outputs.Set(value); err == nil || !strings.Contains(err.Error(), "unknown format") {
+			t.Errorf("expected unknown format error for %q, got: %v", value, err)
+		}
+	}
+}
+
+func TestConvertMultipleOutputs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	out := convertTestdata(t, "testdata/testdata_set.txt", &Options{
+		Format: "openmetrics",
+		Outputs: []Output{
+			{Format: "cobertura", Path: filepath.Join(dir, "xml", "coverage.xml")},
+			{Format: "csv", Path: filepath.Join(dir, "coverage.csv")},
+			{Path: "-"},
+		},
+	})
+
+	data, err := os.ReadFile(filepath.Join(dir, "xml", "coverage.xml"))
+	if err != nil {
+		t.Fatalf("failed to read cobertura report: %v", err)
+	}
+	v := Coverage{}
+	if err := xml.Unmarshal(bytes.TrimPrefix(data, []byte(xml.Header+coberturaDTDDecl)), &v); err != nil {
+		t.Fatalf("failed to decode XML: %v", err)
+	}
+	if v.LinesValid != 4 {
+		t.Errorf("expected 4 valid lines, got %d", v.LinesValid)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "coverage.csv"))
+	if err != nil {
+		t.Fatalf("failed to read csv report: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "package,class,file,function,") {
+		t.Errorf("unexpected csv report:\n%s", data)
+	}
+
+	if !strings.Contains(out.String(), "go_coverage_lines_valid 4\n") {
+		t.Errorf("expected openmetrics report on output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pathKind classifies the code on a line by the path of execution it belongs to.
+type pathKind string
+
+const (
+	pathRegular pathKind = "" // regular logic
+	pathError   pathKind = "error"
+	pathPanic   pathKind = "panic"
+	pathDefer   pathKind = "defer"
+)
+
+// classifyPaths sets the path kind of the lines of method from the AST of its body:
+//
+//   - error: the bodies of if statements that check an error for nil, e.g. the body of
+//     "if err != nil" or "if err != nil && !errors.Is(err, io.EOF)", or the else of
+//     "if err == nil". Errors are recognized by name, as the report has no type
+//     information: see isError.
+//   - panic: statement lists calling panic, log.Panic or log.Fatal.
+//   - defer: defer statements. The bodies of deferred function literals are included
+//     unless closures are reported as functions of their own.
+//
+// All other lines are regular logic. Nested classifications win, e.g. a panic in an
+// error path is a panic.
+func (v *fileVisitor) classifyPaths(body *ast.BlockStmt, method *Method) {
+	if body == nil {
+		return
+	}
+	lines := make(map[int]*Line, len(method.Lines))
+	for _, line := range method.Lines {
+		lines[line.Number] = line
+	}
+	mark := func(node ast.Node, kind pathKind) {
+		if node == nil {
+			return
+		}
+		start := v.fset.PositionFor(node.Pos(), false).Line
+		end := v.fset.PositionFor(node.End(), false).Line
+		for i := start; i <= end; i++ {
+			if line := lines[v.reportedLine(i)]; line != nil {
+				line.path = kind
+			}
+		}
+	}
+	markList := func(list []ast.Stmt, kind pathKind) {
+		for _, stmt := range list {
+			mark(stmt, kind)
+		}
+	}
+
+	// ast.Inspect visits outer nodes first, so nested classifications overwrite outer ones.
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.IfStmt:
+			switch errorCheck(n.Cond) {
+			case token.NEQ:
+				markList(n.Body.List, pathError)
+			case token.EQL:
+				if block, ok := n.Else.(*ast.BlockStmt); ok {
+					markList(block.List, pathError)
+				}
+			}
+		case *ast.BlockStmt:
+			if panics(n.List) {
+				markList(n.List, pathPanic)
+			}
+		case *ast.CaseClause:
+			if panics(n.Body) {
+				markList(n.Body, pathPanic)
+			}
+		case *ast.CommClause:
+			if panics(n.Body) {
+				markList(n.Body, pathPanic)
+			}
+		case *ast.DeferStmt:
+			mark(n, pathDefer)
+		}
+		return true
+	})
+}
+
+// errorCheck returns the operator of a condition comparing an error with nil, like
+// "err != nil", or token.ILLEGAL for any other condition. In conditions combined with &&
+// and ||, the first comparison of an error with nil counts.
+func errorCheck(cond ast.Expr) token.Token {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok {
+		return token.ILLEGAL
+	}
+	if bin.Op == token.LAND || bin.Op == token.LOR {
+		if op := errorCheck(bin.X); op != token.ILLEGAL {
+			return op
+		}
+		return errorCheck(bin.Y)
+	}
+	if bin.Op != token.NEQ && bin.Op != token.EQL {
+		return token.ILLEGAL
+	}
+	x, y := ast.Unparen(bin.X), ast.Unparen(bin.Y)
+	if isNil(x) {
+		x, y = y, x
+	}
+	if !isNil(y) || !isError(x) {
+		return token.ILLEGAL
+	}
+	return bin.Op
+}
+
+func isNil(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
+// isError returns whether expr names an error by convention: err, names ending in Err like
+// readErr or e.Err, names starting with err followed by a capital like errRead, and err
+// prefixed with a single letter like werr. Other names ending in err, like os.Stderr, are
+// not errors.
+func isError(expr ast.Expr) bool {
+	var name string
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	default:
+		return false
+	}
+	switch {
+	case name == "err" || strings.HasSuffix(name, "Err"):
+		return true
+	case strings.HasPrefix(name, "err"):
+		r, _ := utf8.DecodeRuneInString(name[len("err"):])
+		return unicode.IsUpper(r)
+	default:
+		return len(name) == len("err")+1 && strings.HasSuffix(name, "err")
+	}
+}
+
+// panics returns whether a statement list panics or exits, by calling panic, log.Panic or
+// log.Fatal and their formatting variants.
+func panics(list []ast.Stmt) bool {
+	for _, stmt := range list {
+		expr, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			if fun.Name == "panic" {
+				return true
+			}
+		case *ast.SelectorExpr:
+			if pkg, ok := fun.X.(*ast.Ident); ok && pkg.Name == "log" &&
+				(strings.HasPrefix(fun.Sel.Name, "Panic") || strings.HasPrefix(fun.Sel.Name, "Fatal")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pathStats are the line counts of the lines of one or more path kinds.
+type pathStats struct {
+	LinesValid   int64   `json:"lines_valid"`
+	LinesCovered int64   `json:"lines_covered"`
+	LineRate     float64 `json:"line_rate"`
+}
+
+// pathsEntry is the coverage of a package or method split by path kind. Error handling
+// are the error and panic paths, the happy path is the regular logic and defers.
+type pathsEntry struct {
+	ErrorHandling pathStats             `json:"error_handling"`
+	HappyPath     pathStats             `json:"happy_path"`
+	Paths         map[string]*pathStats `json:"paths"`
+}
+
+type pathsPackage struct {
+	Name string `json:"name"`
+	pathsEntry
+	Methods []pathsMethod `json:"methods"`
+}
+
+type pathsMethod struct {
+	Class    string `json:"class"`
+	Function string `json:"function"`
+	File     string `json:"file"`
+	pathsEntry
+}
+
+// newPathsEntry counts the lines by path kind.
+func newPathsEntry(lines Lines) pathsEntry {
+	entry := pathsEntry{Paths: make(map[string]*pathStats)}
+	for _, kind := range []pathKind{pathRegular, pathError, pathPanic, pathDefer} {
+		entry.Paths[kind.String()] = &pathStats{}
+	}
+	for _, line := range lines {
+		stats := []*pathStats{entry.Paths[line.path.String()], &entry.HappyPath}
+		if line.path == pathError || line.path == pathPanic {
+			stats[1] = &entry.ErrorHandling
+		}
+		for _, s := range stats {
+			s.LinesValid++
+			if line.Hits > 0 {
+				s.LinesCovered++
+			}
+		}
+	}
+	for _, s := range entry.Paths {
+		s.LineRate = coverageRate(s.LinesCovered, s.LinesValid)
+	}
+	for _, s := range []*pathStats{&entry.ErrorHandling, &entry.HappyPath} {
+		s.LineRate = coverageRate(s.LinesCovered, s.LinesValid)
+	}
+	return entry
+}
+
+func (kind pathKind) String() string {
+	if kind == pathRegular {
+		return "regular"
+	}
+	return string(kind)
+}
+
+// writePaths writes the coverage of error handling and the happy path of every package
+// and method as JSON.
+func writePaths(out io.Writer, coverage *Coverage, opts *Options) error {
+	report := struct {
+		Packages []pathsPackage `json:"packages"`
+	}{Packages: []pathsPackage{}}
+	for _, pkg := range coverage.Packages {
+		p := pathsPackage{Name: pkg.Name, Methods: []pathsMethod{}}
+		var lines Lines
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				lines = append(lines, method.Lines...)
+				p.Methods = append(p.Methods, pathsMethod{
+					Class:      class.Name,
+					Function:   methodDisplayName(method, opts),
+					File:       method.Filename,
+					pathsEntry: newPathsEntry(method.Lines),
+				})
+			}
+		}
+		p.pathsEntry = newPathsEntry(lines)
+		report.Packages = append(report.Packages, p)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("encode paths report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestConvertPaths(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_paths.txt", &Options{Ignore: &Ignore{}, Format: "paths"})
+
+	var report struct {
+		Packages []pathsPackage `json:"packages"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if len(report.Packages) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(report.Packages))
+	}
+
+	pkg := report.Packages[0]
+	if pkg.ErrorHandling != (pathStats{LinesValid: 2, LinesCovered: 0, LineRate: 0}) {
+		t.Errorf("expected 2 uncovered error handling lines, got %+v", pkg.ErrorHandling)
+	}
+	if pkg.HappyPath != (pathStats{LinesValid: 8, LinesCovered: 7, LineRate: 0.875}) {
+		t.Errorf("expected 7 of 8 happy path lines covered, got %+v", pkg.HappyPath)
+	}
+
+	expected := map[string]pathStats{
+		"regular": {LinesValid: 7, LinesCovered: 6, LineRate: 6.0 / 7},
+		"error":   {LinesValid: 1, LinesCovered: 0, LineRate: 0},
+		"panic":   {LinesValid: 1, LinesCovered: 0, LineRate: 0},
+		"defer":   {LinesValid: 1, LinesCovered: 1, LineRate: 1},
+	}
+	for kind, stats := range expected {
+		if got := pkg.Paths[kind]; got == nil || *got != stats {
+			t.Errorf("expected %s lines %+v, got %+v", kind, stats, got)
+		}
+	}
+
+	if len(pkg.Methods) != 2 {
+		t.Fatalf("expected 2 methods, got %d", len(pkg.Methods))
+	}
+	check := pkg.Methods[1]
+	if check.Function != "check" || check.ErrorHandling.LinesValid != 0 || check.HappyPath.LinesValid != 3 {
+		t.Errorf("expected check to have no error handling lines, got %+v", check)
+	}
+}
+
+func TestErrorCheck(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		cond     string
+		expected token.Token
+	}{
+		{"err != nil", token.NEQ},
+		{"nil != err", token.NEQ},
+		{"(err == nil)", token.EQL},
+		{"err != nil && !errors.Is(err, fs.ErrExist)", token.NEQ},
+		{"ok && readErr != nil", token.NEQ},
+		{"n > 0 || e.Err != nil", token.NEQ},
+		{"errRead != nil", token.NEQ},
+		{"werr != nil", token.NEQ},
+		{"os.Stderr != nil", token.ILLEGAL},
+		{"stderr != nil", token.ILLEGAL},
+		{"errors != nil", token.ILLEGAL},
+		{"err != io.EOF", token.ILLEGAL},
+		{"n > 0 && ok", token.ILLEGAL},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.cond, func(t *testing.T) {
+			t.Parallel()
+
+			cond, err := parser.ParseExpr(tc.cond)
+			if err != nil {
+				t.Fatalf("failed to parse condition: %v", err)
+			}
+			if op := errorCheck(cond); op != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, op)
+			}
+		})
+	}
+}
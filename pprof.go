@@ -0,0 +1,191 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// writePprof writes the hit counts of the report as gzipped profile.proto, the format
+// read by go tool pprof. Every line of a function with hits is a location, with the hit
+// count of the line as sample value. This is mostly useful for profiles recorded with
+// -covermode=count or atomic, where the hit counts are execution frequencies.
+func writePprof(out io.Writer, coverage *Coverage, opts *Options) error {
+	paths := make(map[string]string, len(coverage.Files))
+	for _, file := range coverage.Files {
+		paths[file.Name] = file.Path
+	}
+
+	p := newPprofBuilder()
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				name := pkg.Name + "." + methodDisplayName(method, opts)
+				filename := paths[method.Filename]
+				if filename == "" {
+					filename = method.Filename
+				}
+				p.addFunction(name, filename, method)
+			}
+		}
+	}
+
+	zw := gzip.NewWriter(out)
+	if _, err := zw.Write(p.encode()); err != nil {
+		return fmt.Errorf("write profile: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("write profile: %w", err)
+	}
+	return nil
+}
+
+// Field numbers of the messages in profile.proto, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	pprofProfileSampleType  = 1
+	pprofProfileSample      = 2
+	pprofProfileLocation    = 4
+	pprofProfileFunction    = 5
+	pprofProfileStringTable = 6
+	pprofProfilePeriodType  = 11
+	pprofProfilePeriod      = 12
+
+	pprofValueTypeType = 1
+	pprofValueTypeUnit = 2
+
+	pprofSampleLocationID = 1
+	pprofSampleValue      = 2
+
+	pprofLocationID   = 1
+	pprofLocationLine = 4
+
+	pprofLineFunctionID = 1
+	pprofLineLine       = 2
+
+	pprofFunctionID         = 1
+	pprofFunctionName       = 2
+	pprofFunctionSystemName = 3
+	pprofFunctionFilename   = 4
+	pprofFunctionStartLine  = 5
+)
+
+// pprofBuilder collects the functions, locations and samples of a profile and encodes
+// them as profile.proto message.
+type pprofBuilder struct {
+	stringTable []string
+	stringIDs   map[string]int64
+	functions   protoBuffer
+	locations   protoBuffer
+	samples     protoBuffer
+	numFuncs    uint64
+	numLocs     uint64
+}
+
+func newPprofBuilder() *pprofBuilder {
+	return &pprofBuilder{
+		stringTable: []string{""},
+		stringIDs:   map[string]int64{"": 0},
+	}
+}
+
+func (p *pprofBuilder) stringID(s string) int64 {
+	id, ok := p.stringIDs[s]
+	if !ok {
+		id = int64(len(p.stringTable))
+		p.stringTable = append(p.stringTable, s)
+		p.stringIDs[s] = id
+	}
+	return id
+}
+
+func (p *pprofBuilder) addFunction(name, filename string, method *Method) {
+	p.numFuncs++
+	funcID := p.numFuncs
+	var fn protoBuffer
+	fn.putUint(pprofFunctionID, funcID)
+	fn.putInt(pprofFunctionName, p.stringID(name))
+	fn.putInt(pprofFunctionSystemName, p.stringID(name))
+	fn.putInt(pprofFunctionFilename, p.stringID(filename))
+	fn.putInt(pprofFunctionStartLine, int64(method.StartLine))
+	p.functions.putMessage(pprofProfileFunction, fn)
+
+	for _, line := range method.Lines {
+		if line.Hits == 0 {
+			continue
+		}
+		p.numLocs++
+		var l protoBuffer
+		l.putUint(pprofLineFunctionID, funcID)
+		l.putInt(pprofLineLine, int64(line.Number))
+		var loc protoBuffer
+		loc.putUint(pprofLocationID, p.numLocs)
+		loc.putMessage(pprofLocationLine, l)
+		p.locations.putMessage(pprofProfileLocation, loc)
+
+		var sample protoBuffer
+		sample.putUint(pprofSampleLocationID, p.numLocs)
+		sample.putInt(pprofSampleValue, line.Hits)
+		p.samples.putMessage(pprofProfileSample, sample)
+	}
+}
+
+func (p *pprofBuilder) encode() []byte {
+	var valueType protoBuffer
+	valueType.putInt(pprofValueTypeType, p.stringID("hits"))
+	valueType.putInt(pprofValueTypeUnit, p.stringID("count"))
+
+	var profile protoBuffer
+	profile.putMessage(pprofProfileSampleType, valueType)
+	profile = append(profile, p.samples...)
+	profile = append(profile, p.locations...)
+	profile = append(profile, p.functions...)
+	for _, s := range p.stringTable {
+		profile.putString(pprofProfileStringTable, s)
+	}
+	profile.putMessage(pprofProfilePeriodType, valueType)
+	profile.putInt(pprofProfilePeriod, 1)
+	return profile
+}
+
+// protoBuffer is a minimal protocol buffer encoder for the few wire types used by
+// profile.proto.
+type protoBuffer []byte
+
+const (
+	protoWireVarint = 0
+	protoWireBytes  = 2
+)
+
+func (b *protoBuffer) varint(v uint64) {
+	for v >= 0x80 {
+		*b = append(*b, byte(v)|0x80)
+		v >>= 7
+	}
+	*b = append(*b, byte(v))
+}
+
+func (b *protoBuffer) key(field, wireType int) {
+	b.varint(uint64(field<<3 | wireType))
+}
+
+func (b *protoBuffer) putUint(field int, v uint64) {
+	b.key(field, protoWireVarint)
+	b.varint(v)
+}
+
+func (b *protoBuffer) putInt(field int, v int64) {
+	b.putUint(field, uint64(v))
+}
+
+func (b *protoBuffer) putString(field int, s string) {
+	b.key(field, protoWireBytes)
+	b.varint(uint64(len(s)))
+	*b = append(*b, s...)
+}
+
+func (b *protoBuffer) putMessage(field int, m protoBuffer) {
+	b.key(field, protoWireBytes)
+	b.varint(uint64(len(m)))
+	*b = append(*b, m...)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+)
+
+func TestProtoBuffer(t *testing.T) {
+	t.Parallel()
+
+	var b protoBuffer
+	b.putUint(1, 150)
+	b.putString(2, "testing")
+	var m protoBuffer
+	m.putInt(1, -1)
+	b.putMessage(3, m)
+
+	expected := []byte{
+		0x08, 0x96, 0x01,
+		0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g',
+		0x1a, 0x0b, 0x08, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+	}
+	if !bytes.Equal(b, expected) {
+		t.Errorf("expected encoding %x, got %x", expected, []byte(b))
+	}
+}
+
+func TestPprofBuilderStringTable(t *testing.T) {
+	t.Parallel()
+
+	p := newPprofBuilder()
+	if id := p.stringID(""); id != 0 {
+		t.Errorf("expected empty string to have id 0, got %d", id)
+	}
+	foo := p.stringID("foo")
+	bar := p.stringID("bar")
+	if foo == bar || p.stringID("foo") != foo {
+		t.Errorf("expected stable and distinct ids, got foo=%d bar=%d", foo, bar)
+	}
+}
+
+func TestConvertPprof(t *testing.T) {
+	t.Parallel()
+
+	out := convertTestdata(t, "testdata/testdata_set.txt", &Options{Format: "pprof"})
+
+	zr, err := gzip.NewReader(out)
+	if err != nil {
+		t.Fatalf("failed to open gzip stream: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("failed to read gzip stream: %v", err)
+	}
+
+	for _, s := range []string{
+		"github.com/fasmat/gocover-cobertura/testdata.Func1",
+		"github.com/fasmat/gocover-cobertura/testdata.Type1.Func2a",
+		"func1.go",
+		"hits",
+		"count",
+	} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("expected string %q in profile", s)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	sarifRuleUncovered   = "uncovered-function"
+	sarifRuleLowCoverage = "low-coverage-function"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+// writeSARIF writes a SARIF 2.1.0 log with one result for every function that is not
+// covered at all or whose line rate is below opts.SARIFThreshold percent. The line rate is
+// the statement rate if opts.StatementRates is set, like in the Cobertura report.
+func writeSARIF(out io.Writer, coverage *Coverage, opts *Options) error {
+	results := []sarifResult{}
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				result, ok := sarifMethodResult(method, opts)
+				if ok {
+					results = append(results, result)
+				}
+			}
+		}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gocover-cobertura",
+				InformationURI: "https://github.com/fasmat/gocover-cobertura",
+				Rules: []sarifRule{
+					{
+						ID:               sarifRuleUncovered,
+						ShortDescription: sarifMessage{Text: "Function is not covered by tests"},
+					},
+					{
+						ID:               sarifRuleLowCoverage,
+						ShortDescription: sarifMessage{Text: "Function has insufficient test coverage"},
+					},
+				},
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("encode SARIF log: %w", err)
+	}
+	return nil
+}
+
+func sarifMethodResult(method *Method, opts *Options) (sarifResult, bool) {
+	if method.NumLines() == 0 {
+		return sarifResult{}, false
+	}
+
+	name := methodDisplayName(method, opts)
+
+	kind := "line"
+	if opts.StatementRates {
+		kind = "statement"
+	}
+
+	var result sarifResult
+	switch rate := float64(method.LineRate) * 100; {
+	case method.NumLinesWithHits() == 0:
+		result.RuleID = sarifRuleUncovered
+		result.Message.Text = fmt.Sprintf("Function %s is not covered by tests", name)
+	case rate < opts.SARIFThreshold:
+		result.RuleID = sarifRuleLowCoverage
+		result.Message.Text = fmt.Sprintf("Function %s has a %s coverage of %.1f%%, below the threshold of %.1f%%",
+			name, kind, rate, opts.SARIFThreshold)
+	default:
+		return sarifResult{}, false
+	}
+
+	result.Level = "warning"
+	result.Locations = []sarifLocation{{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: method.Filename, URIBaseID: "%SRCROOT%"},
+			Region:           sarifRegion{StartLine: method.StartLine, EndLine: method.EndLine},
+		},
+	}}
+	return result, true
+}
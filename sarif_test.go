@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestConvertSARIF(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name      string
+		threshold float64
+		expected  map[string]string
+	}{
+		{
+			name:      "uncovered",
+			threshold: 0,
+			expected: map[string]string{
+				"Function Func3 is not covered by tests": sarifRuleUncovered,
+			},
+		},
+		{
+			name:      "threshold",
+			threshold: 60,
+			expected: map[string]string{
+				"Function Func1 has a line coverage of 50.0%, below the threshold of 60.0%": sarifRuleLowCoverage,
+				"Function Func3 is not covered by tests":                                    sarifRuleUncovered,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := convertTestdata(t, "testdata/testdata_set.txt", &Options{
+				Ignore:         &Ignore{Files: regexp.MustCompile(`[\\/]func[45]\.go$`)},
+				Format:         "sarif",
+				SARIFThreshold: tc.threshold,
+			})
+
+			var log sarifLog
+			if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+				t.Fatalf("failed to decode SARIF: %v", err)
+			}
+			if log.Version != sarifVersion {
+				t.Errorf("expected version %s, got %s", sarifVersion, log.Version)
+			}
+			if len(log.Runs) != 1 {
+				t.Fatalf("expected 1 run, got %d", len(log.Runs))
+			}
+
+			results := log.Runs[0].Results
+			if len(results) != len(tc.expected) {
+				t.Fatalf("expected %d results, got %d", len(tc.expected), len(results))
+			}
+			for _, result := range results {
+				rule, ok := tc.expected[result.Message.Text]
+				if !ok {
+					t.Errorf("unexpected result %q", result.Message.Text)
+					continue
+				}
+				if result.RuleID != rule {
+					t.Errorf("expected rule %s for %q, got %s", rule, result.Message.Text, result.RuleID)
+				}
+			}
+
+			loc := results[0].Locations[0].PhysicalLocation
+			if !slices.Contains([]string{"testdata/func1.go", "testdata/func3.go"}, loc.ArtifactLocation.URI) {
+				t.Errorf("unexpected artifact location %s", loc.ArtifactLocation.URI)
+			}
+			if loc.Region.StartLine == 0 || loc.Region.EndLine < loc.Region.StartLine {
+				t.Errorf("unexpected region %+v", loc.Region)
+			}
+		})
+	}
+}
+
+func TestConvertSARIFStatementRates(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		name           string
+		statementRates bool
+		expected       []string
+	}{
+		{
+			name:     "lines",
+			expected: []string{"Function Statements has a line coverage of 50.0%, below the threshold of 55.0%"},
+		},
+		{
+			name:           "statements",
+			statementRates: true,
+			expected:       []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := convertTestdata(t, "testdata/testdata_statements.txt", &Options{
+				Ignore:         &Ignore{},
+				Format:         "sarif",
+				SARIFThreshold: 55,
+				StatementRates: tc.statementRates,
+			})
+
+			var log sarifLog
+			if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+				t.Fatalf("failed to decode SARIF: %v", err)
+			}
+			messages := []string{}
+			for _, result := range log.Runs[0].Results {
+				messages = append(messages, result.Message.Text)
+			}
+			if !slices.Equal(messages, tc.expected) {
+				t.Errorf("expected results %v, got %v", tc.expected, messages)
+			}
+		})
+	}
+}
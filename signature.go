@@ -0,0 +1,188 @@
+package main
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// signatureStyles maps the names accepted by -signatures to the functions rendering the
+// signature of a function declaration in that style.
+var signatureStyles = map[string]func(n *ast.FuncDecl) string{
+	"go":   goSignature,
+	"jvm":  jvmSignature,
+	"none": func(*ast.FuncDecl) string { return "" },
+}
+
+// goSignature renders the signature of a function like its declaration in Go, without
+// parameter names and body, e.g. "func (*List[T]) Push(T) error".
+func goSignature(n *ast.FuncDecl) string {
+	var sb strings.Builder
+	sb.WriteString("func ")
+	if n.Recv != nil && len(n.Recv.List) > 0 {
+		sb.WriteString("(" + types.ExprString(n.Recv.List[0].Type) + ") ")
+	}
+	sb.WriteString(n.Name.Name)
+	if n.Type.TypeParams != nil {
+		params := make([]string, 0, len(n.Type.TypeParams.List))
+		for _, field := range n.Type.TypeParams.List {
+			names := make([]string, 0, len(field.Names))
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+			params = append(params, strings.Join(names, ", ")+" "+types.ExprString(field.Type))
+		}
+		sb.WriteString("[" + strings.Join(params, ", ") + "]")
+	}
+	sb.WriteString("(" + strings.Join(fieldTypes(n.Type.Params), ", ") + ")")
+
+	results := fieldTypes(n.Type.Results)
+	switch len(results) {
+	case 0:
+	case 1:
+		sb.WriteString(" " + results[0])
+	default:
+		sb.WriteString(" (" + strings.Join(results, ", ") + ")")
+	}
+	return sb.String()
+}
+
+// fieldTypes returns the type of every parameter in fields, repeated for parameters
+// declared together like "a, b int".
+func fieldTypes(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var result []string
+	for _, field := range fields.List {
+		typ := types.ExprString(field.Type)
+		for range max(len(field.Names), 1) {
+			result = append(result, typ)
+		}
+	}
+	return result
+}
+
+// jvmSignature renders the signature of a function as JVM method descriptor for tools
+// that expect one, e.g. "(Ljava/lang/Object;)Lerror;". Go types are mapped to the JVM
+// type of the same size where there is one, type parameters are erased to Object and
+// multiple results are returned as Object array. The receiver is not part of the
+// descriptor, like this is not part of JVM descriptors.
+func jvmSignature(n *ast.FuncDecl) string {
+	typeParams := make(map[string]bool)
+	for _, list := range []*ast.FieldList{n.Type.TypeParams, receiverTypeParams(n)} {
+		if list == nil {
+			continue
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				typeParams[name.Name] = true
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(")
+	if n.Type.Params != nil {
+		for _, field := range n.Type.Params.List {
+			typ := jvmType(field.Type, typeParams)
+			for range max(len(field.Names), 1) {
+				sb.WriteString(typ)
+			}
+		}
+	}
+	sb.WriteString(")")
+
+	results := fieldTypes(n.Type.Results)
+	switch len(results) {
+	case 0:
+		sb.WriteString("V")
+	case 1:
+		sb.WriteString(jvmType(n.Type.Results.List[0].Type, typeParams))
+	default:
+		sb.WriteString("[Ljava/lang/Object;")
+	}
+	return sb.String()
+}
+
+// receiverTypeParams returns the type parameters of a generic receiver like List[T] as
+// field list.
+func receiverTypeParams(n *ast.FuncDecl) *ast.FieldList {
+	if n.Recv == nil || len(n.Recv.List) == 0 {
+		return nil
+	}
+	typ := n.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	var indices []ast.Expr
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		indices = t.Indices
+	}
+	list := &ast.FieldList{}
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			list.List = append(list.List, &ast.Field{Names: []*ast.Ident{ident}})
+		}
+	}
+	return list
+}
+
+// jvmBasicTypes maps the predeclared Go types to JVM descriptors.
+var jvmBasicTypes = map[string]string{
+	"bool":    "Z",
+	"byte":    "B",
+	"int8":    "B",
+	"uint8":   "B",
+	"int16":   "S",
+	"uint16":  "C",
+	"rune":    "I",
+	"int32":   "I",
+	"uint32":  "I",
+	"int":     "J",
+	"uint":    "J",
+	"int64":   "J",
+	"uint64":  "J",
+	"uintptr": "J",
+	"float32": "F",
+	"float64": "D",
+	"string":  "Ljava/lang/String;",
+	"any":     "Ljava/lang/Object;",
+}
+
+func jvmType(expr ast.Expr, typeParams map[string]bool) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if typeParams[t.Name] {
+			return "Ljava/lang/Object;"
+		}
+		if basic, ok := jvmBasicTypes[t.Name]; ok {
+			return basic
+		}
+		return "L" + t.Name + ";"
+	case *ast.SelectorExpr:
+		return "L" + types.ExprString(t.X) + "/" + t.Sel.Name + ";"
+	case *ast.StarExpr:
+		return jvmType(t.X, typeParams)
+	case *ast.ParenExpr:
+		return jvmType(t.X, typeParams)
+	case *ast.Ellipsis:
+		return "[" + jvmType(t.Elt, typeParams)
+	case *ast.ArrayType:
+		return "[" + jvmType(t.Elt, typeParams)
+	case *ast.IndexExpr:
+		return jvmType(t.X, typeParams)
+	case *ast.IndexListExpr:
+		return jvmType(t.X, typeParams)
+	case *ast.MapType:
+		return "Ljava/util/Map;"
+	case *ast.FuncType:
+		return "Ljava/util/function/Function;"
+	default:
+		// Channels, interfaces and struct literals have no counterpart.
+		return "Ljava/lang/Object;"
+	}
+}
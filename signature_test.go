@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestSignatures(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		decl   string
+		goSig  string
+		jvmSig string
+	}{
+		{
+			decl:   `func init() {}`,
+			goSig:  "func init()",
+			jvmSig: "()V",
+		},
+		{
+			decl:   `func (r *Type1) Func2a(arg1 *int) {}`,
+			goSig:  "func (*Type1) Func2a(*int)",
+			jvmSig: "(J)V",
+		},
+		{
+			decl:   `func Parse(a, b string, r io.Reader, opts ...Option) (int, error) {}`,
+			goSig:  "func Parse(string, string, io.Reader, ...Option) (int, error)",
+			jvmSig: "(Ljava/lang/String;Ljava/lang/String;Lio/Reader;[LOption;)[Ljava/lang/Object;",
+		},
+		{
+			decl:   `func Map[T, U any](s []T, f func(T) U) []U {}`,
+			goSig:  "func Map[T, U any]([]T, func(T) U) []U",
+			jvmSig: "([Ljava/lang/Object;Ljava/util/function/Function;)[Ljava/lang/Object;",
+		},
+		{
+			decl:   `func (l *List[K, V]) Get(key K) (v V) {}`,
+			goSig:  "func (*List[K, V]) Get(K) V",
+			jvmSig: "(Ljava/lang/Object;)Ljava/lang/Object;",
+		},
+		{
+			decl:   `func Lookup(m map[string]bool, ch chan int) bool {}`,
+			goSig:  "func Lookup(map[string]bool, chan int) bool",
+			jvmSig: "(Ljava/util/Map;Ljava/lang/Object;)Z",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.goSig, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\n"+tc.decl, 0)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+			fn := parsed.Decls[0].(*ast.FuncDecl)
+			if sig := signatureStyles["go"](fn); sig != tc.goSig {
+				t.Errorf("expected go signature %q, got %q", tc.goSig, sig)
+			}
+			if sig := signatureStyles["jvm"](fn); sig != tc.jvmSig {
+				t.Errorf("expected jvm signature %q, got %q", tc.jvmSig, sig)
+			}
+			if sig := signatureStyles["none"](fn); sig != "" {
+				t.Errorf("expected no signature, got %q", sig)
+			}
+		})
+	}
+}
+
+func TestConvertUnknownSignatureStyle(t *testing.T) {
+	t.Parallel()
+
+	err := convert(strings.NewReader("mode: set"), new(bytes.Buffer), &Options{
+		Ignore:         &Ignore{},
+		SignatureStyle: "foo",
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown signature style "foo"`) {
+		t.Fatalf("expected error about unknown signature style, got: %v", err)
+	}
+}
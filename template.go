@@ -0,0 +1,126 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"text/template"
+)
+
+// rated is implemented by all parts of the coverage model that have a line coverage.
+type rated interface {
+	HitRate() float32
+	NumLinesWithHits() int64
+}
+
+// templateFuncs are the helper functions available in templates given with -template.
+var templateFuncs = template.FuncMap{
+	// percent formats a rate from 0.0 to 1.0 as percentage with one decimal.
+	"percent": func(rate any) (string, error) {
+		switch r := rate.(type) {
+		case float32:
+			return fmt.Sprintf("%.1f", r*100), nil
+		case float64:
+			return fmt.Sprintf("%.1f", r*100), nil
+		default:
+			return "", fmt.Errorf("percent of %T, expected a float", rate)
+		}
+	},
+	// sortByName returns a copy of a slice of packages, classes or methods sorted by name.
+	"sortByName": func(items any) (any, error) {
+		return sortTemplateItems(items, func(a, b reflect.Value) int {
+			return cmp.Compare(a.Elem().FieldByName("Name").String(), b.Elem().FieldByName("Name").String())
+		})
+	},
+	// sortByRate returns a copy of a slice of packages, classes or methods sorted by line
+	// rate, least covered first.
+	"sortByRate": func(items any) (any, error) {
+		return sortTemplateItems(items, func(a, b reflect.Value) int {
+			return cmp.Compare(templateRate(a), templateRate(b))
+		})
+	},
+	// below returns the packages, classes or methods with a line rate below percent.
+	"below": func(percent float64, items any) (any, error) {
+		return filterTemplateItems(items, func(item reflect.Value) bool {
+			return templateRate(item)*100 < percent
+		})
+	},
+	// uncovered returns the packages, classes or methods with a line rate of 0.
+	"uncovered": func(items any) (any, error) {
+		return filterTemplateItems(items, func(item reflect.Value) bool {
+			return templateRate(item) == 0
+		})
+	},
+	// limit returns at most the first n elements of a slice.
+	"limit": func(n int, items any) (any, error) {
+		v := reflect.ValueOf(items)
+		if v.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("limit of %T, expected a slice", items)
+		}
+		return v.Slice(0, min(n, v.Len())).Interface(), nil
+	},
+}
+
+// parseTemplate reads and parses the template in the given file.
+func parseTemplate(name string) (*template.Template, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(name)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// writeTemplate writes the result of executing opts.Template on the coverage model.
+func writeTemplate(out io.Writer, coverage *Coverage, opts *Options) error {
+	if opts.Template == nil {
+		return errors.New("no template given, see -template")
+	}
+	if err := opts.Template.Execute(out, coverage); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	return nil
+}
+
+// templateRate returns the line rate of a package, class or method as reported, which is
+// the statement rate with -rates statements.
+func templateRate(item reflect.Value) float64 {
+	return item.Elem().FieldByName("LineRate").Float()
+}
+
+func sortTemplateItems(items any, compare func(a, b reflect.Value) int) (any, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice || !v.Type().Elem().Implements(reflect.TypeFor[rated]()) {
+		return nil, fmt.Errorf("cannot sort %T, expected a slice of packages, classes or methods", items)
+	}
+	sorted := make([]reflect.Value, v.Len())
+	for i := range sorted {
+		sorted[i] = v.Index(i)
+	}
+	slices.SortStableFunc(sorted, compare)
+
+	result := reflect.MakeSlice(v.Type(), 0, v.Len())
+	return reflect.Append(result, sorted...).Interface(), nil
+}
+
+func filterTemplateItems(items any, keep func(reflect.Value) bool) (any, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice || !v.Type().Elem().Implements(reflect.TypeFor[rated]()) {
+		return nil, fmt.Errorf("cannot filter %T, expected a slice of packages, classes or methods", items)
+	}
+	result := reflect.MakeSlice(v.Type(), 0, v.Len())
+	for i := range v.Len() {
+		if keep(v.Index(i)) {
+			result = reflect.Append(result, v.Index(i))
+		}
+	}
+	return result.Interface(), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	t.Parallel()
+
+	// The reported rates are used, like the statement rate of b, not the share of lines with hits.
+	methods := []*Method{
+		{Name: "b", LineRate: 0.75, Lines: Lines{{Number: 1, Hits: 1}, {Number: 2, Hits: 0}}},
+		{Name: "c", Lines: Lines{{Number: 1, Hits: 0}}},
+		{Name: "a", LineRate: 1, Lines: Lines{{Number: 1, Hits: 1}}},
+	}
+
+	tt := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"percent", `{{ percent 0.4567 }}`, "45.7"},
+		{"sortByName", `{{ range sortByName . }}{{ .Name }}{{ end }}`, "abc"},
+		{"sortByRate", `{{ range sortByRate . }}{{ .Name }}{{ end }}`, "cba"},
+		{"below", `{{ range below 60.0 . }}{{ .Name }}{{ end }}`, "c"},
+		{"below rate", `{{ range below 80.0 . }}{{ .Name }}{{ end }}`, "bc"},
+		{"uncovered", `{{ range uncovered . }}{{ .Name }}{{ end }}`, "c"},
+		{"limit", `{{ range limit 2 . }}{{ .Name }}{{ end }}`, "bc"},
+		{"limit more", `{{ range limit 5 . }}{{ .Name }}{{ end }}`, "bca"},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl := template.Must(template.New(tc.name).Funcs(templateFuncs).Parse(tc.text))
+			out := new(bytes.Buffer)
+			if err := tmpl.Execute(out, methods); err != nil {
+				t.Fatalf("execute failed: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestTemplateFuncsErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, text := range []string{
+		`{{ percent "foo" }}`,
+		`{{ sortByName "foo" }}`,
+		`{{ sortByRate 1 }}`,
+		`{{ below 50.0 "foo" }}`,
+		`{{ limit 1 "foo" }}`,
+	} {
+		tmpl := template.Must(template.New("test").Funcs(templateFuncs).Parse(text))
+		if err := tmpl.Execute(new(bytes.Buffer), nil); err == nil {
+			t.Errorf("expected error executing %s", text)
+		}
+	}
+}
+
+func TestConvertTemplate(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := parseTemplate("testdata/report.tmpl")
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+
+	out := convertTestdata(t, "testdata/testdata_set.txt", &Options{
+		Ignore:   &Ignore{Files: regexp.MustCompile(`[\\/]func[45]\.go$`)},
+		Format:   "template",
+		Template: tmpl,
+	})
+
+	expected := `Total: 50.0%
+github.com/fasmat/gocover-cobertura/testdata: 50.0%
+  -: 0.0%
+    uncovered: Func3
+  -: 50.0%
+`
+	if out.String() != expected {
+		t.Errorf("unexpected template output:\n%s", out.String())
+	}
+}
+
+func TestConvertTemplateMissing(t *testing.T) {
+	t.Parallel()
+
+	err := convert(strings.NewReader("mode: set"), new(bytes.Buffer), &Options{Ignore: &Ignore{}, Format: "template"})
+	if err == nil || !strings.Contains(err.Error(), "no template given") {
+		t.Fatalf("expected error about missing template, got: %v", err)
+	}
+}
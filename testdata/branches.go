@@ -0,0 +1,51 @@
+//go:build testdata
+
+package testdata
+
+func If(a, b bool) int {
+	if a && b {
+		return 1
+	}
+	return 0
+}
+
+func IfElse(a int) string {
+	if a > 0 {
+		return "positive"
+	} else if a < 0 {
+		return "negative"
+	} else {
+		return "zero"
+	}
+}
+
+func Switch(a int) string {
+	switch a {
+	case 1:
+		return "one"
+	case 2:
+		return "two"
+	}
+	return "many"
+}
+
+func For(n int) int {
+	sum := 0
+	for i := 0; i < n || sum > 100; i++ {
+		sum += i
+	}
+	return sum
+}
+
+func Select(ch chan int) int {
+	select {
+	case v := <-ch:
+		return v
+	default:
+		return -1
+	}
+}
+
+func Straight(a int) int {
+	return a + 1
+}
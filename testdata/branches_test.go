@@ -0,0 +1,17 @@
+//go:build testdata
+
+package testdata
+
+import (
+	"testing"
+)
+
+func TestBranches(t *testing.T) {
+	If(true, true)
+	If(false, true)
+	IfElse(1)
+	Switch(1)
+	Switch(5)
+	For(3)
+	Select(nil)
+}
@@ -0,0 +1,36 @@
+//go:build testdata
+
+package testdata
+
+var Handler = func(a int) int {
+	if a > 0 {
+		return 1
+	}
+	return 0
+}
+
+func Outer(a int) int {
+	double := func() int {
+		return a * 2
+	}
+	inc := func() int {
+		add := func(b int) int {
+			return b + 1
+		}
+		return add(a)
+	}
+	return double() + inc()
+}
+
+func Twice(a int) int {
+	f := func(x int) int { return x * 2 }
+	return f(a)
+}
+
+var _ = func() int {
+	return 1
+}()
+
+var _ = func() int {
+	return 2
+}()
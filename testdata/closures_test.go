@@ -0,0 +1,12 @@
+//go:build testdata
+
+package testdata
+
+import (
+	"testing"
+)
+
+func TestClosures(t *testing.T) {
+	Handler(1)
+	Outer(1)
+}
@@ -0,0 +1,11 @@
+//go:build testdata
+
+package testdata
+
+func Func2e() int {
+	return 2
+}
+
+func (r *Type1) Func2d(arg1 *int) {
+	*arg1 = 2
+}
@@ -0,0 +1,12 @@
+//go:build testdata
+
+package testdata
+
+import (
+	"testing"
+)
+
+func TestGeneric(t *testing.T) {
+	var l List[int]
+	l.Push(1)
+}
@@ -0,0 +1,11 @@
+//go:build testdata
+
+package testdata
+
+//line grammar.y:3
+func Parse(s string) int {
+	if s == "" {
+		return 0
+	}
+	return len(s)
+}
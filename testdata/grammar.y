@@ -0,0 +1,9 @@
+%%
+
+input: /* empty */
+	{ if s == "" {
+		return 0
+	} }
+	| WORD { return len(s) }
+	;
+%%
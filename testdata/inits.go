@@ -0,0 +1,13 @@
+//go:build testdata
+
+package testdata
+
+var initialized int
+
+func init() {
+	initialized++
+}
+
+func init() {
+	initialized++
+}
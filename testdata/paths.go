@@ -0,0 +1,27 @@
+//go:build testdata
+
+package testdata
+
+import (
+	"errors"
+	"log"
+)
+
+func Paths(a int) (int, error) {
+	err := check(a)
+	if err != nil {
+		return 0, err
+	}
+	defer log.Print("done")
+	if a > 10 {
+		panic("too large")
+	}
+	return a, nil
+}
+
+func check(a int) error {
+	if a < 0 {
+		return errors.New("negative")
+	}
+	return nil
+}
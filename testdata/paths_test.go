@@ -0,0 +1,11 @@
+//go:build testdata
+
+package testdata
+
+import (
+	"testing"
+)
+
+func TestPaths(t *testing.T) {
+	Paths(1)
+}
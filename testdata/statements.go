@@ -0,0 +1,11 @@
+//go:build testdata
+
+package testdata
+
+func Statements(a int) int {
+	double := func() int { return a * 2 }
+	if a > 0 {
+		return double()
+	}
+	return 0
+}
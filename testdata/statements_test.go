@@ -0,0 +1,11 @@
+//go:build testdata
+
+package testdata
+
+import (
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	Statements(0)
+}